@@ -0,0 +1,25 @@
+// Command flog writes fake logs in the formats of the flog package to stdout,
+// plain files or gzip files, e.g. to feed file-based collectors such as Alloy
+// or promtail. See flog --help.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/grafana/explore-logs/generator/flog"
+)
+
+func main() {
+	opts := flog.ParseOptions()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := flog.GenerateContext(ctx, opts, os.Stdout); err != nil {
+		log.Fatalf("flog: %v", err)
+	}
+}
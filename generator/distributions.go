@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// distributionServices emit logfmt lines whose numeric fields are drawn from
+// known distributions, for checking unwrap, quantile_over_time and field
+// statistics. -describe and -list -list-format=json print the expected quantiles.
+var distributionServices = []struct {
+	name        model.LabelValue
+	description string
+	msg         string
+	fields      []log.FieldDistribution
+}{
+	{
+		name:        "api-latency",
+		description: "Request latency (normal) and response size (log-normal)",
+		msg:         "request served",
+		fields: []log.FieldDistribution{
+			{Field: "latency", Unit: log.UnitMilliseconds, Dist: log.Normal{Mean: 200, StdDev: 30}},
+			{Field: "response_size", Unit: log.UnitBytes, Dist: log.LogNormal{Mu: 10, Sigma: 1.2}},
+		},
+	},
+	{
+		name:        "queue-worker",
+		description: "Queue wait time (exponential), payload size in kB (Pareto) and score (bimodal, scientific notation)",
+		msg:         "batch processed",
+		fields: []log.FieldDistribution{
+			{Field: "wait", Unit: log.UnitDuration, Dist: log.Exponential{Mean: 2000}},
+			{Field: "payload_kb", Dist: log.Pareto{Xm: 10, Alpha: 1.5}},
+			{Field: "score", Unit: log.UnitScientific, Dist: log.Bimodal{A: log.Normal{Mean: 1500, StdDev: 100}, B: log.Normal{Mean: 90000, StdDev: 5000}, P: 0.8}},
+		},
+	},
+	{
+		name:        "cache-latency",
+		description: "Cache lookup latency that steps from ~5ms to ~50ms and back every 10 minutes, and hit latency (bimodal)",
+		msg:         "cache lookup",
+		fields: []log.FieldDistribution{
+			{Field: "duration", Unit: log.UnitDuration, Dist: log.Steps{Period: 10 * time.Minute, Phases: []log.Distribution{log.Normal{Mean: 5, StdDev: 1}, log.Normal{Mean: 50, StdDev: 10}}}},
+			{Field: "lookup_ms", Unit: log.UnitMilliseconds, Dist: log.Bimodal{A: log.Exponential{Mean: 2}, B: log.Normal{Mean: 120, StdDev: 20}, P: 0.9}},
+		},
+	},
+}
+
+func init() {
+	for _, svc := range distributionServices {
+		fieldNames := []string{"level", "msg"}
+		for _, f := range svc.fields {
+			fieldNames = append(fieldNames, f.Field)
+		}
+		register(ServiceInfo{
+			Namespace:     "distributions",
+			Name:          svc.name,
+			Description:   svc.description,
+			Format:        "logfmt",
+			Fields:        fieldNames,
+			Metadata:      defaultMetadata,
+			Distributions: svc.fields,
+		}, distributionGenerator(svc.msg, svc.fields))
+	}
+}
+
+// distributionGenerator returns a LogGenerator writing a logfmt line with a
+// value of every field per line.
+func distributionGenerator(msg string, fields []log.FieldDistribution) LogGenerator {
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		logger.Schedule(func(t time.Time) {
+			var b strings.Builder
+			fmt.Fprintf(&b, "level=info msg=%q", msg)
+			for _, f := range fields {
+				fmt.Fprintf(&b, " %s=%s", f.Field, f.Value(t))
+			}
+			logger.LogWithMetadata(log.INFO, t, b.String(), metadata)
+		})
+	}
+}
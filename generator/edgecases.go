@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+)
+
+// Weighted line kinds of the edge-cases services, as set by -edge-cases,
+// -json-errors and -logfmt-errors.
+var (
+	edgeCases         = log.EdgeCases
+	jsonParseErrors   = log.JSONParseErrors
+	logfmtParseErrors = log.LogfmtParseErrors
+)
+
+// Opt-in flags of the edge-cases services, see -edge-case-lines and
+// -parse-errors.
+var (
+	edgeCaseLines         bool
+	jsonParseErrorLines   bool
+	logfmtParseErrorLines bool
+)
+
+var edgeCaseMetadata = append(append([]string{}, defaultMetadata...), "edge_case")
+
+func init() {
+	register(ServiceInfo{
+		Namespace:   "edge-cases",
+		Name:        "edge-case-lines",
+		Description: "Adversarial line content: lines near and beyond Loki's max line size, newlines, tabs, ANSI codes, invalid UTF-8, NUL bytes, CJK, RTL, emoji, zero-width characters and huge tokens; opt-in with -edge-case-lines or -edge-cases",
+		Format:      "text",
+		Metadata:    edgeCaseMetadata,
+		optIn:       &edgeCaseLines,
+	}, edgeCaseGenerator(&edgeCases))
+	register(ServiceInfo{
+		Namespace:   "edge-cases",
+		Name:        "json-parse-errors",
+		Description: "JSON lines mixed with truncated JSON, top-level arrays, duplicate keys, deep nesting, out of range numbers and keys colliding with labels; opt-in with -parse-errors or -json-errors",
+		Format:      "json",
+		Fields:      []string{"level", "msg", "status", "duration_ms", "user", "err", "retry", "bytes", "namespace", "service_name", "cluster", "env", "pod"},
+		Metadata:    edgeCaseMetadata,
+		optIn:       &jsonParseErrorLines,
+	}, edgeCaseGenerator(&jsonParseErrors))
+	register(ServiceInfo{
+		Namespace:   "edge-cases",
+		Name:        "logfmt-parse-errors",
+		Description: "logfmt lines mixed with unbalanced quotes, empty keys, bad escapes and keys colliding with labels; opt-in with -parse-errors or -logfmt-errors",
+		Format:      "logfmt",
+		Fields:      []string{"level", "msg", "status", "duration", "user", "err", "retry", "namespace", "service_name", "cluster", "env", "pod", "traceID"},
+		Metadata:    edgeCaseMetadata,
+		optIn:       &logfmtParseErrorLines,
+	}, edgeCaseGenerator(&logfmtParseErrors))
+}
+
+// edgeCaseGenerator returns a LogGenerator emitting the line kinds in cases
+// by weight, with the kind in the edge_case structured metadata. cases is
+// read when the generator starts, after flags are parsed.
+func edgeCaseGenerator(cases *[]log.EdgeCase) LogGenerator {
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		kinds := *cases
+		logger.Schedule(func(t time.Time) {
+			c, ok := log.RandEdgeCase(kinds)
+			if !ok {
+				return
+			}
+			level := log.INFO
+			if c.Name == "multiline" {
+				level = log.ERROR
+			}
+			md := append(append(push.LabelsAdapter{}, metadata...), push.LabelAdapter{Name: "edge_case", Value: c.Name})
+			logger.LogWithMetadata(level, t, c.Line(), md)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package flog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+)
+
+// NewLog creates a log line of format with creation time t.
+func NewLog(format string, t time.Time) string {
+	return global().NewLog(format, t)
+}
+
+// NewLog creates a log line of format with creation time t.
+func (g *Generator) NewLog(format string, t time.Time) string {
+	switch format {
+	case "apache_common":
+		return g.NewApacheCommonLog(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "apache_combined":
+		return g.NewApacheCombinedLog(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "apache_error":
+		return g.NewApacheErrorLog(t)
+	case "rfc3164":
+		return g.NewRFC3164Log(t)
+	case "rfc5424":
+		return g.NewRFC5424Log(t)
+	case "common_log":
+		return g.NewCommonLogFormat(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "json":
+		return g.NewJSONLogFormat(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "logfmt":
+		return g.NewLogfmtLog(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "iis_w3c":
+		return g.NewIISW3CLog(t, g.RandResourceURI(), g.HTTPStatusCode())
+	case "cef":
+		return g.NewCEFLog(t, g.Number(0, 10))
+	case "leef":
+		return g.NewLEEFLog(t, g.Number(1, 10))
+	case "gelf":
+		return g.NewGELFLog(t, g.Number(0, 7))
+	default:
+		return ""
+	}
+}
+
+// Generate writes the logs described by option: to w for the stdout type,
+// to option.Output otherwise.
+func Generate(option *Option, w io.Writer) error {
+	return GenerateContext(context.Background(), option, w)
+}
+
+// GenerateContext is Generate, stopping early when ctx is done. Files are
+// closed either way, so gzip output stays readable.
+//
+// Generation stops after option.Number lines or, when option.Bytes is set,
+// after the line reaching option.Bytes bytes; option.Forever ignores both.
+// Line timestamps start now and advance by option.Sleep, or by option.Delay
+// when Sleep is zero, while option.Delay actually pauses between lines.
+// With option.SplitBy, a new file is started every SplitBy lines, or every
+// SplitBy bytes when option.Bytes is set: generated.log, generated1.log, ...
+// A non-zero option.Seed makes the lines the same on every run, and
+// option.Pools configures the value pools of the lines, leaving the pools of
+// other generators untouched.
+func GenerateContext(ctx context.Context, option *Option, w io.Writer) (err error) {
+	if _, err := ParseFormat(option.Format); err != nil {
+		return err
+	}
+	interval := option.Sleep
+	if interval == 0 {
+		interval = option.Delay
+	}
+
+	pools, err := log.ParsePoolConfigs(option.Pools)
+	if err != nil {
+		return err
+	}
+	g := global()
+	if option.Seed != 0 {
+		g = newGenerator(rand.New(rand.NewSource(option.Seed)), pools)
+	} else if len(pools) > 0 {
+		g = newGenerator(g.rand, pools)
+	}
+
+	out := &splitWriter{option: option, stdout: w}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	created := time.Now()
+	var lines, bytes int
+	for option.Forever || (option.Bytes == 0 && lines < option.Number) || (option.Bytes > 0 && bytes < option.Bytes) {
+		if option.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(option.Delay):
+			}
+		} else if ctx.Err() != nil {
+			return nil
+		}
+		line := g.NewLog(option.Format, created) + "\n"
+		if err := out.WriteLine(line); err != nil {
+			return err
+		}
+		lines++
+		bytes += len(line)
+		created = created.Add(interval)
+	}
+	return nil
+}
+
+// NewSplitFileName returns the name of the count-th split of path, e.g.
+// generated2.log for generated.log.
+func NewSplitFileName(path string, count int) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + strconv.Itoa(count) + ext
+}
+
+// splitWriter writes lines to the stdout writer, or to the output files of
+// option, starting a new file every option.SplitBy lines or bytes.
+type splitWriter struct {
+	option *Option
+	stdout io.Writer
+
+	files     int
+	file      *os.File
+	gz        *gzip.Writer
+	buf       *bufio.Writer
+	fileLines int
+	fileBytes int
+}
+
+func (s *splitWriter) WriteLine(line string) error {
+	if s.option.Type == "stdout" {
+		_, err := io.WriteString(s.stdout, line)
+		return err
+	}
+	if s.buf == nil || s.full() {
+		if err := s.Close(); err != nil {
+			return err
+		}
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	s.fileLines++
+	s.fileBytes += len(line)
+	_, err := s.buf.WriteString(line)
+	return err
+}
+
+func (s *splitWriter) full() bool {
+	if s.option.SplitBy <= 0 {
+		return false
+	}
+	if s.option.Bytes > 0 {
+		return s.fileBytes >= s.option.SplitBy
+	}
+	return s.fileLines >= s.option.SplitBy
+}
+
+func (s *splitWriter) open() error {
+	name := s.option.Output
+	if s.files > 0 {
+		name = NewSplitFileName(s.option.Output, s.files)
+	}
+	if !s.option.Overwrite {
+		if _, err := os.Stat(name); err == nil {
+			return fmt.Errorf("%s already exists, use --overwrite to replace it", name)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	s.files++
+	s.file = f
+	s.fileLines, s.fileBytes = 0, 0
+	if s.option.Type == "gz" {
+		s.gz = gzip.NewWriter(f)
+		s.buf = bufio.NewWriter(s.gz)
+	} else {
+		s.buf = bufio.NewWriter(f)
+	}
+	return nil
+}
+
+// Close flushes and closes the current file, if any.
+func (s *splitWriter) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.buf.Flush()
+	if s.gz != nil {
+		err = errors.Join(err, s.gz.Close())
+	}
+	err = errors.Join(err, s.file.Close())
+	s.file, s.gz, s.buf = nil, nil, nil
+	return err
+}
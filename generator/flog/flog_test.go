@@ -0,0 +1,136 @@
+package flog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogFormats(t *testing.T) {
+	for _, format := range validFormats {
+		assert.NotEmpty(t, NewLog(format, time.Now()), format)
+	}
+}
+
+func TestGenerateNumber(t *testing.T) {
+	opts := defaultOptions()
+	opts.Number = 25
+	var buf bytes.Buffer
+	require.NoError(t, Generate(opts, &buf))
+	assert.Equal(t, 25, strings.Count(buf.String(), "\n"))
+}
+
+func TestGenerateBytes(t *testing.T) {
+	opts := defaultOptions()
+	opts.Bytes = 4096
+	var buf bytes.Buffer
+	require.NoError(t, Generate(opts, &buf))
+	assert.GreaterOrEqual(t, buf.Len(), 4096)
+	lines := strings.SplitAfter(buf.String(), "\n")
+	last := len(lines[len(lines)-2])
+	assert.Less(t, buf.Len()-last, 4096, "stops at the line reaching the limit")
+}
+
+func TestGenerateSleepSpacesTimestamps(t *testing.T) {
+	opts := defaultOptions()
+	opts.Format = "rfc5424"
+	opts.Number = 3
+	opts.Sleep = time.Hour
+	var buf bytes.Buffer
+	start := time.Now()
+	require.NoError(t, Generate(opts, &buf))
+	assert.Less(t, time.Since(start), time.Second, "sleep does not pause")
+
+	var times []time.Time
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		ts, err := time.Parse(RFC5424, strings.Fields(line)[1])
+		require.NoError(t, err)
+		times = append(times, ts)
+	}
+	assert.Equal(t, time.Hour, times[1].Sub(times[0]))
+	assert.Equal(t, time.Hour, times[2].Sub(times[1]))
+}
+
+func TestGenerateDelayPaces(t *testing.T) {
+	opts := defaultOptions()
+	opts.Number = 5
+	opts.Delay = 10 * time.Millisecond
+	start := time.Now()
+	require.NoError(t, Generate(opts, &bytes.Buffer{}))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestGenerateSplitFiles(t *testing.T) {
+	dir := t.TempDir()
+	opts := defaultOptions()
+	opts.Type = "log"
+	opts.Output = filepath.Join(dir, "nested", "generated.log")
+	opts.Number = 25
+	opts.SplitBy = 10
+	require.NoError(t, Generate(opts, nil))
+
+	for name, lines := range map[string]int{"generated.log": 10, "generated1.log": 10, "generated2.log": 5} {
+		content, err := os.ReadFile(filepath.Join(dir, "nested", name))
+		require.NoError(t, err)
+		assert.Equal(t, lines, strings.Count(string(content), "\n"), name)
+	}
+	_, err := os.Stat(filepath.Join(dir, "nested", "generated3.log"))
+	assert.True(t, os.IsNotExist(err), "no empty trailing file")
+
+	assert.ErrorContains(t, Generate(opts, nil), "already exists")
+	opts.Overwrite = true
+	assert.NoError(t, Generate(opts, nil))
+}
+
+func TestGenerateGzip(t *testing.T) {
+	opts := defaultOptions()
+	opts.Type = "gz"
+	opts.Output = filepath.Join(t.TempDir(), "generated.log.gz")
+	opts.Number = 100
+	require.NoError(t, Generate(opts, nil))
+
+	f, err := os.Open(opts.Output)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(gz)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, 100, lines)
+}
+
+func TestGeneratePoolsLeaveGlobalPools(t *testing.T) {
+	before := log.PoolConfigOf(log.PoolResources)
+	opts := defaultOptions()
+	opts.Number = 20
+	opts.Pools = "resources:size=1"
+	var buf bytes.Buffer
+	require.NoError(t, Generate(opts, &buf))
+
+	uris := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		// host - user [date zone] "method uri protocol" ...
+		uris[strings.Fields(line)[6]] = true
+	}
+	assert.Len(t, uris, 1, "lines draw from the configured pool")
+	assert.Equal(t, before, log.PoolConfigOf(log.PoolResources), "global pools are not configured")
+}
+
+func TestGenerateRejectsUnknownFormat(t *testing.T) {
+	opts := defaultOptions()
+	opts.Format = "xml"
+	assert.Error(t, Generate(opts, &bytes.Buffer{}))
+}
@@ -0,0 +1,77 @@
+package flog
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/grafana/explore-logs/generator/log"
+)
+
+// Generator creates the log lines of flog from an injected random source:
+// generators with sources seeded alike create the same lines, whatever else
+// draws random numbers meanwhile. Like its source, a Generator is not safe
+// for concurrent use, so give each stream its own.
+//
+// The package functions (NewApacheCommonLog, FakeIP, ...) use a Generator
+// drawing from the math/rand global source.
+type Generator struct {
+	// Faker draws the fake values (addresses, users, ...) from rand.
+	*gofakeit.Faker
+	rand *rand.Rand
+
+	// ips and resourceURIs are the pools of FakeIP and RandResourceURI,
+	// drawn from the source when the Generator is created, with the size
+	// and skew of the log.PoolIPs and log.PoolResources pools.
+	ips          *log.Pool
+	resourceURIs *log.Pool
+}
+
+// NewGenerator returns a Generator drawing from r, e.g.
+// NewGenerator(rand.New(rand.NewSource(seed))).
+func NewGenerator(r *rand.Rand) *Generator {
+	return newGenerator(r, nil)
+}
+
+// newGenerator returns a Generator drawing from r whose pools are configured
+// by pools, or by the global configuration (see log.ConfigurePools) for the
+// pools not in pools.
+func newGenerator(r *rand.Rand, pools map[string]log.PoolConfig) *Generator {
+	g := &Generator{Faker: gofakeit.NewFaker(r, false), rand: r}
+	g.ips = g.pool(pools, log.PoolIPs, 5, g.IPv4Address)
+	g.resourceURIs = g.pool(pools, log.PoolResources, 20, g.randResourceURI)
+	return g
+}
+
+// pool draws the values of the named pool with value, size of them unless
+// configured otherwise.
+func (g *Generator) pool(pools map[string]log.PoolConfig, name string, size int, value func() string) *log.Pool {
+	cfg, ok := pools[name]
+	if !ok {
+		cfg = log.PoolConfigOf(name)
+	}
+	if cfg.Size > 0 {
+		size = cfg.Size
+	}
+	values := make([]string, size)
+	for i := range values {
+		values[i] = value()
+	}
+	return log.NewPool(values, cfg)
+}
+
+// global is the Generator of the package functions. It is created on first
+// use, so its pools are drawn after the global source is seeded (see
+// log.EnableStatic).
+var global = sync.OnceValue(func() *Generator {
+	return newGenerator(rand.New(globalSource{}), nil)
+})
+
+// globalSource is a rand.Source64 drawing from the math/rand global source,
+// which, unlike a rand.Rand source, is safe for concurrent use. Seed does
+// nothing: the global source is seeded by its owner.
+type globalSource struct{}
+
+func (globalSource) Int63() int64   { return rand.Int63() }
+func (globalSource) Uint64() uint64 { return rand.Uint64() }
+func (globalSource) Seed(int64)     {}
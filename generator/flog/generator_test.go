@@ -0,0 +1,71 @@
+package flog
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seeded(seed int64) *Generator {
+	return NewGenerator(rand.New(rand.NewSource(seed)))
+}
+
+func TestGeneratorSameSeed(t *testing.T) {
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	a, b := seeded(7), seeded(7)
+	assert.Equal(t, a.ips, b.ips, "pools are drawn from the seed")
+	assert.Equal(t, a.resourceURIs, b.resourceURIs, "pools are drawn from the seed")
+	for i := 0; i < 20; i++ {
+		for _, format := range validFormats {
+			assert.Equal(t, a.NewLog(format, ts), b.NewLog(format, ts), format)
+		}
+		assert.Equal(t, a.NewShoppingCartOrder(ts), b.NewShoppingCartOrder(ts))
+		assert.Equal(t, a.UserAgent(), b.UserAgent())
+	}
+
+	assert.NotEqual(t, seeded(7).NewLog("json", ts), seeded(8).NewLog("json", ts))
+}
+
+func TestGeneratorIsolated(t *testing.T) {
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	a, b := seeded(7), seeded(7)
+	other := seeded(7)
+	for i := 0; i < 10; i++ {
+		// Neither other generators nor the global source move a generator.
+		other.NewLog("apache_combined", ts)
+		NewLog("rfc5424", ts)
+		rand.Int63()
+		assert.Equal(t, a.NewLog("rfc5424", ts), b.NewLog("rfc5424", ts))
+	}
+}
+
+func TestTemplateRendererSeeded(t *testing.T) {
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	tpl := MustTemplate("seeded", `{{ipv4}} {{user}} {{uuid}} {{choice "a" "b" "c"}} {{round (normal 10 2) 2}} {{resource}}`)
+	a, b := tpl.Renderer(seeded(3)), tpl.Renderer(seeded(3))
+	for i := 0; i < 10; i++ {
+		lineA, err := a.Execute(ts, "info")
+		assert.NoError(t, err)
+		lineB, err := b.Execute(ts, "info")
+		assert.NoError(t, err)
+		assert.Equal(t, lineA, lineB)
+	}
+}
+
+func TestGeneratorPools(t *testing.T) {
+	t.Cleanup(func() { _ = log.ConfigurePools("ips:uniform,size=5;resources:uniform,size=20") })
+	require.NoError(t, log.ConfigurePools("ips:size=50,zipf=2;resources:size=3"))
+	g := seeded(7)
+	assert.Len(t, g.ips.Values(), 50)
+	assert.Len(t, g.resourceURIs.Values(), 3)
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[g.FakeIP()]++
+	}
+	assert.Greater(t, counts[g.ips.Values()[0]], 5000, "the first IP dominates")
+}
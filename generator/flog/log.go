@@ -3,11 +3,13 @@ package flog
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/brianvoe/gofakeit"
+	"github.com/grafana/explore-logs/generator/log"
 	"github.com/grafana/loki/pkg/push"
 )
 
@@ -24,111 +26,275 @@ const (
 	RFC5424Log = "<%d>%d %s %s %s %d ID%d %s %s"
 	// CommonLogFormat : {host} {user-identifier} {auth-user-id} [{datetime}] "{method} {request} {protocol}" {response-code} {bytes}
 	CommonLogFormat = "%s - %s [%s] \"%s %s %s\" %d %d"
-  // JSONLogFormat : {"host": "{host}", "user-identifier": "{user-identifier}", "datetime": "{datetime}", "method": "{method}", "request": "{request}", "protocol": "{protocol}", "status": {status}, "bytes": {bytes}, "referer": "{referer}", "_25values": "{_25values}", "msg": "{msg}", "nested_object": "{nested_object}"}
-	JSONLogFormat         = `{"host":"%s", "user-identifier":"%s", "datetime":"%s", "method": "%s", "request": "%s", "protocol":"%s", "status":%d, "bytes":"%dMB", "referer": "%s", "_25values": %d, "msg":"%s", "nested_object": %s}`
+	// JSONLogFormat : {"host": "{host}", "user-identifier": "{user-identifier}", "datetime": "{datetime}", "method": "{method}", "request": "{request}", "protocol": "{protocol}", "status": {status}, "bytes": {bytes}, "referer": "{referer}", "_25values": "{_25values}", "msg": "{msg}", "nested_object": "{nested_object}"}
+	JSONLogFormat = `{"host":%s, "user-identifier":%s, "datetime":%s, "method": %s, "request": %s, "protocol":%s, "status":%d, "bytes":"%dMB", "referer": %s, "_25values": %d, "msg":%s, "nested_object": %s}`
+	// LogfmtLog : ts={timestamp} level={level} msg="{message}" method={method} path={request} status={status} duration={duration} bytes={bytes} ratio={ratio} cached={bool} user={user}
+	LogfmtLog = `ts=%s level=%s msg=%q method=%s path=%s status=%d duration=%s bytes=%d ratio=%.3f cached=%t user=%s`
+	// IISW3CLog : {date} {time} {s-ip} {cs-method} {cs-uri-stem} {cs-uri-query} {s-port} {cs-username} {c-ip} {cs(User-Agent)} {cs(Referer)} {sc-status} {sc-substatus} {sc-win32-status} {time-taken}
+	IISW3CLog = "%s %s %s %s %s %s %d %s %s %s %s %d %d %d %d"
+	// IISW3CFields is the directive naming the fields of IISW3CLog lines in W3C extended log files.
+	IISW3CFields = "#Fields: date time s-ip cs-method cs-uri-stem cs-uri-query s-port cs-username c-ip cs(User-Agent) cs(Referer) sc-status sc-substatus sc-win32-status time-taken"
+	// CEFLog : CEF:0|{vendor}|{product}|{version}|{signature-id}|{name}|{severity}|{extension}
+	CEFLog = "CEF:0|%s|%s|%s|%d|%s|%d|%s"
+	// LEEFLog : LEEF:1.0|{vendor}|{product}|{version}|{event-id}|{tab separated attributes}
+	LEEFLog = "LEEF:1.0|%s|%s|%s|%s|%s"
+	// GELFLog : {"version":"1.1","host":"{host}","short_message":"{message}","full_message":"{details}","timestamp":{unix-seconds},"level":{syslog-severity},"_{field}":{value}...}
+	GELFLog               = `{"version":"1.1","host":%s,"short_message":%s,"full_message":%s,"timestamp":%.3f,"level":%d,%s}`
 	ShoppingCartLogFormat = "Order %d successfully placed, customerId: %s, price: %f, paymentMethod: %s, shippingMethod: %s, shippingCountry: %s"
 )
 
 // NewApacheCommonLog creates a log string with apache common log format
 func NewApacheCommonLog(t time.Time, URI string, statusCode int) string {
+	return global().NewApacheCommonLog(t, URI, statusCode)
+}
+
+// NewApacheCommonLog creates a log string with apache common log format
+func (g *Generator) NewApacheCommonLog(t time.Time, URI string, statusCode int) string {
 	return fmt.Sprintf(
 		ApacheCommonLog,
-		gofakeit.IPv4Address(),
-		RandAuthUserID(),
+		g.IPv4Address(),
+		g.RandAuthUserID(),
 		t.Format(Apache),
-		gofakeit.HTTPMethod(),
+		g.HTTPMethod(),
 		URI,
-		RandHTTPVersion(),
+		g.RandHTTPVersion(),
 		statusCode,
-		gofakeit.Number(0, 30000),
+		g.Number(0, 30000),
 	)
 }
 
-var ips = []string{
-	gofakeit.IPv4Address(),
-	gofakeit.IPv4Address(),
-	gofakeit.IPv4Address(),
-	gofakeit.IPv4Address(),
-	gofakeit.IPv4Address(),
-}
-
-func FakeIP() string {
-	return ips[rand.Intn(len(ips))]
+// NewApacheCombinedLog creates a log string with apache combined log format
+func NewApacheCombinedLog(t time.Time, URI string, statusCode int) string {
+	return global().NewApacheCombinedLog(t, URI, statusCode)
 }
 
 // NewApacheCombinedLog creates a log string with apache combined log format
-func NewApacheCombinedLog(t time.Time, URI string, statusCode int) string {
+func (g *Generator) NewApacheCombinedLog(t time.Time, URI string, statusCode int) string {
 	return fmt.Sprintf(
 		ApacheCombinedLog,
-		ips[rand.Intn(len(ips))],
-		RandAuthUserID(),
+		g.FakeIP(),
+		g.RandAuthUserID(),
 		t.Format(Apache),
-		gofakeit.HTTPMethod(),
+		g.HTTPMethod(),
 		URI,
-		RandHTTPVersion(),
+		g.RandHTTPVersion(),
 		statusCode,
-		gofakeit.Number(30, 100000),
-		gofakeit.URL(),
-		gofakeit.UserAgent(),
+		g.Number(30, 100000),
+		g.URL(),
+		g.UserAgent(),
 	)
 }
 
 // NewApacheErrorLog creates a log string with apache error log format
 func NewApacheErrorLog(t time.Time) string {
+	return global().NewApacheErrorLog(t)
+}
+
+// NewApacheErrorLog creates a log string with apache error log format
+func (g *Generator) NewApacheErrorLog(t time.Time) string {
+	return g.NewApacheErrorLogWithSeverity(t, g.LogLevel("apache"))
+}
+
+// NewApacheErrorLogWithSeverity creates a log string with apache error log
+// format and the given severity (e.g. "error", "warn", "notice")
+func NewApacheErrorLogWithSeverity(t time.Time, severity string) string {
+	return global().NewApacheErrorLogWithSeverity(t, severity)
+}
+
+// NewApacheErrorLogWithSeverity creates a log string with apache error log
+// format and the given severity (e.g. "error", "warn", "notice")
+func (g *Generator) NewApacheErrorLogWithSeverity(t time.Time, severity string) string {
 	return fmt.Sprintf(
 		ApacheErrorLog,
 		t.Format(ApacheError),
-		gofakeit.Word(),
-		gofakeit.LogLevel("apache"),
-		gofakeit.Number(1, 10000),
-		gofakeit.Number(1, 10000),
-		gofakeit.IPv4Address(),
-		gofakeit.Number(1, 65535),
-		gofakeit.HackerPhrase(),
+		g.Word(),
+		severity,
+		g.Number(1, 10000),
+		g.Number(1, 10000),
+		g.IPv4Address(),
+		g.Number(1, 65535),
+		g.HackerPhrase(),
 	)
 }
 
 // NewRFC3164Log creates a log string with syslog (RFC3164) format
 func NewRFC3164Log(t time.Time) string {
+	return global().NewRFC3164Log(t)
+}
+
+// NewRFC3164Log creates a log string with syslog (RFC3164) format
+func (g *Generator) NewRFC3164Log(t time.Time) string {
+	return g.NewRFC3164LogWithPriority(t, g.Number(0, 191))
+}
+
+// NewRFC3164LogWithPriority creates a log string with syslog (RFC3164)
+// format and the given priority (facility*8 + severity)
+func NewRFC3164LogWithPriority(t time.Time, priority int) string {
+	return global().NewRFC3164LogWithPriority(t, priority)
+}
+
+// NewRFC3164LogWithPriority creates a log string with syslog (RFC3164)
+// format and the given priority (facility*8 + severity)
+func (g *Generator) NewRFC3164LogWithPriority(t time.Time, priority int) string {
 	return fmt.Sprintf(
 		RFC3164Log,
-		gofakeit.Number(0, 191),
+		priority,
 		t.Format(RFC3164),
-		strings.ToLower(gofakeit.Username()),
-		gofakeit.Word(),
-		gofakeit.Number(1, 10000),
-		gofakeit.HackerPhrase(),
+		strings.ToLower(g.Username()),
+		g.Word(),
+		g.Number(1, 10000),
+		g.HackerPhrase(),
 	)
 }
 
 // NewRFC5424Log creates a log string with syslog (RFC5424) format
 func NewRFC5424Log(t time.Time) string {
+	return global().NewRFC5424Log(t)
+}
+
+// NewRFC5424Log creates a log string with syslog (RFC5424) format
+func (g *Generator) NewRFC5424Log(t time.Time) string {
+	return g.NewRFC5424LogWithPriority(t, g.Number(0, 191))
+}
+
+// NewRFC5424LogWithPriority creates a log string with syslog (RFC5424)
+// format and the given priority (facility*8 + severity)
+func NewRFC5424LogWithPriority(t time.Time, priority int) string {
+	return global().NewRFC5424LogWithPriority(t, priority)
+}
+
+// NewRFC5424LogWithPriority creates a log string with syslog (RFC5424)
+// format and the given priority (facility*8 + severity)
+func (g *Generator) NewRFC5424LogWithPriority(t time.Time, priority int) string {
 	return fmt.Sprintf(
 		RFC5424Log,
-		gofakeit.Number(0, 191),
-		gofakeit.Number(1, 3),
-		t.Format(RFC5424),
-		gofakeit.DomainName(),
-		gofakeit.Word(),
-		gofakeit.Number(1, 10000),
-		gofakeit.Number(1, 1000),
-		"-", // TODO: structured data
-		gofakeit.HackerPhrase(),
+		priority,
+		g.Number(1, 3),
+		t.UTC().Format(RFC5424),
+		g.DomainName(),
+		g.Word(),
+		g.Number(1, 10000),
+		g.Number(1, 1000),
+		g.RandStructuredData(),
+		g.HackerPhrase(),
 	)
 }
 
+// sdElements are the SD-IDs of RFC5424 structured data elements with their
+// parameters: the IANA registered ones, and private ones of the form
+// name@<private enterprise number>.
+var sdElements = []struct {
+	id     string
+	params map[string]func(g *Generator) string
+}{
+	{"timeQuality", map[string]func(g *Generator) string{
+		"tzKnown":      func(g *Generator) string { return strconv.Itoa(g.rand.Intn(2)) },
+		"isSynced":     func(g *Generator) string { return strconv.Itoa(g.rand.Intn(2)) },
+		"syncAccuracy": func(g *Generator) string { return strconv.Itoa(g.Number(1000, 1000000)) },
+	}},
+	{"origin", map[string]func(g *Generator) string{
+		"ip":           (*Generator).FakeIP,
+		"enterpriseId": func(g *Generator) string { return strconv.Itoa(g.Number(1, 60000)) },
+		"software":     (*Generator).Word,
+		"swVersion": func(g *Generator) string {
+			return fmt.Sprintf("%d.%d.%d", g.rand.Intn(5), g.rand.Intn(20), g.rand.Intn(10))
+		},
+	}},
+	{"meta", map[string]func(g *Generator) string{
+		"sequenceId": func(g *Generator) string { return strconv.Itoa(g.Number(1, 2147483647)) },
+		"sysUpTime":  func(g *Generator) string { return strconv.Itoa(g.Number(0, 10000000)) },
+		"language":   func(g *Generator) string { return g.RandomString([]string{"en", "en-US", "de", "fr", "ja"}) },
+	}},
+	{"exampleSDID@32473", map[string]func(g *Generator) string{
+		"iut":         func(g *Generator) string { return strconv.Itoa(g.Number(1, 9)) },
+		"eventSource": func(g *Generator) string { return g.RandomString([]string{"Application", "Security", "System"}) },
+		"eventID":     func(g *Generator) string { return strconv.Itoa(g.Number(1000, 9999)) },
+	}},
+	{"request@53595", map[string]func(g *Generator) string{
+		"method": (*Generator).HTTPMethod,
+		"path":   (*Generator).RandResourceURI,
+		"status": func(g *Generator) string { return strconv.Itoa(g.HTTPStatusCode()) },
+		"user":   func(g *Generator) string { return strings.ToLower(g.Username()) },
+	}},
+	{"quote@53595", map[string]func(g *Generator) string{
+		// Values with the characters RFC5424 requires to be escaped.
+		"msg":  func(g *Generator) string { return `said "hello" [world] C:\temp` },
+		"path": func(g *Generator) string { return `C:\Grafana\logs` },
+	}},
+}
+
+// RandStructuredData returns RFC5424 structured data: "-" or up to three
+// elements with distinct SD-IDs and a random subset of their parameters.
+func RandStructuredData() string {
+	return global().RandStructuredData()
+}
+
+// RandStructuredData returns RFC5424 structured data: "-" or up to three
+// elements with distinct SD-IDs and a random subset of their parameters.
+func (g *Generator) RandStructuredData() string {
+	n := g.rand.Intn(4)
+	if n == 0 {
+		return "-"
+	}
+	var b strings.Builder
+	for _, i := range g.rand.Perm(len(sdElements))[:n] {
+		element := sdElements[i]
+		b.WriteString("[" + element.id)
+		names := make([]string, 0, len(element.params))
+		for name := range element.params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if g.rand.Intn(4) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, ` %s="%s"`, name, log.EscapeSDParam(element.params[name](g)))
+		}
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
 // NewCommonLogFormat creates a log string with common log format
 func NewCommonLogFormat(t time.Time, URI string, statusCode int) string {
+	return global().NewCommonLogFormat(t, URI, statusCode)
+}
+
+// NewCommonLogFormatRequest creates a log string with common log format for a
+// request with the given method and URI.
+func NewCommonLogFormatRequest(t time.Time, method, URI string, statusCode int) string {
+	return global().NewCommonLogFormatRequest(t, method, URI, statusCode)
+}
+
+// NewCommonLogFormatRequest creates a log string with common log format for
+// a request with the given method and URI.
+func (g *Generator) NewCommonLogFormatRequest(t time.Time, method, URI string, statusCode int) string {
+	return fmt.Sprintf(
+		CommonLogFormat,
+		g.IPv4Address(),
+		g.RandAuthUserID(),
+		t.Format(CommonLog),
+		method,
+		URI,
+		g.RandHTTPVersion(),
+		statusCode,
+		g.Number(0, 30000),
+	)
+}
+
+// NewCommonLogFormat creates a log string with common log format
+func (g *Generator) NewCommonLogFormat(t time.Time, URI string, statusCode int) string {
 	return fmt.Sprintf(
 		CommonLogFormat,
-		gofakeit.IPv4Address(),
-		RandAuthUserID(),
+		g.IPv4Address(),
+		g.RandAuthUserID(),
 		t.Format(CommonLog),
-		gofakeit.HTTPMethod(),
+		g.HTTPMethod(),
 		URI,
-		RandHTTPVersion(),
+		g.RandHTTPVersion(),
 		statusCode,
-		gofakeit.Number(0, 30000),
+		g.Number(0, 30000),
 	)
 }
 
@@ -155,13 +321,13 @@ type NestedJsonObject struct {
 }
 
 // Helper function to initialize BaseObject
-func newBaseObject() BaseObject {
+func (g *Generator) newBaseObject() BaseObject {
 	return BaseObject{
-		Method:         gofakeit.HTTPMethod(),
-		Url:            gofakeit.URL(),
-		UserIdentifier: gofakeit.Username(),
-		NumArray:       []int{gofakeit.Number(0, 30000), gofakeit.Number(0, 30000), gofakeit.Number(0, 30000)},
-		StrArray:       []string{gofakeit.Word(), gofakeit.Word(), gofakeit.Word()},
+		Method:         g.HTTPMethod(),
+		Url:            g.URL(),
+		UserIdentifier: g.Username(),
+		NumArray:       []int{g.Number(0, 30000), g.Number(0, 30000), g.Number(0, 30000)},
+		StrArray:       []string{g.Word(), g.Word(), g.Word()},
 	}
 }
 
@@ -182,7 +348,7 @@ var sentenceWeights = []int{
 }
 
 // weightedRandomSentence returns a random sentence from the sentences slice with weights
-func weightedRandomSentence() string {
+func (g *Generator) weightedRandomSentence() string {
 	// Calculate total weight
 	totalWeight := 0
 	for _, weight := range sentenceWeights {
@@ -190,7 +356,7 @@ func weightedRandomSentence() string {
 	}
 
 	// Generate random number between 0 and totalWeight
-	r := rand.Intn(totalWeight)
+	r := g.rand.Intn(totalWeight)
 
 	// Find the sentence based on the random number
 	for i, weight := range sentenceWeights {
@@ -206,76 +372,367 @@ func weightedRandomSentence() string {
 
 // NewJSONLogFormat creates a log string with json log format
 func NewJSONLogFormat(t time.Time, URI string, statusCode int) string {
+	return global().NewJSONLogFormat(t, URI, statusCode)
+}
+
+// NewJSONLogFormat creates a log string with json log format
+func (g *Generator) NewJSONLogFormat(t time.Time, URI string, statusCode int) string {
 	nestedJsonObject := &NestedJsonObject{
-		BaseObject: newBaseObject(),
+		BaseObject: g.newBaseObject(),
 		DeeplyNestedObject: DeeplyNestedObject{
-			BaseObject: newBaseObject(),
+			BaseObject: g.newBaseObject(),
 			ExtraDeeplyNestedObject: ExtraDeeplyNestedObject{
-				BaseObject: newBaseObject(),
+				BaseObject: g.newBaseObject(),
 			},
 		},
 	}
 	nestedJson, _ := json.Marshal(nestedJsonObject)
 
-  // JSONLogFormat : {"host": "{host}", "user-identifier": "{user-identifier}", "datetime": "{datetime}", "method": "{method}", "request": "{request}", "protocol": "{protocol}", "status": {status}, "bytes": {bytes}, "referer": "{referer}", "_25values": "{_25values}", "msg": "{msg}", "nested_object": "{nested_object}"}
+	// JSONLogFormat : {"host": "{host}", "user-identifier": "{user-identifier}", "datetime": "{datetime}", "method": "{method}", "request": "{request}", "protocol": "{protocol}", "status": {status}, "bytes": {bytes}, "referer": "{referer}", "_25values": "{_25values}", "msg": "{msg}", "nested_object": "{nested_object}"}
 	return fmt.Sprintf(
 		JSONLogFormat,
-		ips[rand.Intn(len(ips))],
-		RandAuthUserID(),
-		t.Format(CommonLog),
-		gofakeit.HTTPMethod(),
-		URI,
-		RandHTTPVersion(),
+		jsonString(g.FakeIP()),
+		jsonString(g.RandAuthUserID()),
+		jsonString(t.Format(CommonLog)),
+		jsonString(g.HTTPMethod()),
+		jsonString(URI),
+		jsonString(g.RandHTTPVersion()),
 		statusCode,
-		gofakeit.Number(0, 300),
-		gofakeit.URL(),
-		gofakeit.Number(0, 25),
-		weightedRandomSentence(),
+		g.Number(0, 300),
+		jsonString(g.URL()),
+		g.Number(0, 25),
+		jsonString(g.weightedRandomSentence()),
 		nestedJson,
 	)
 }
 
-// "Order %d successfully placed, customerId: %s, price: %f, paymentMethod: %s, shippingMethod: %s, shippingCountry: %s"
-func NewShoppingCart(t time.Time) string {
+// NewLogfmtLog creates a log string with logfmt format and typed fields:
+// integers, floats, durations, booleans and quoted strings. The level
+// follows the status code.
+func NewLogfmtLog(t time.Time, URI string, statusCode int) string {
+	return global().NewLogfmtLog(t, URI, statusCode)
+}
+
+// NewLogfmtLog creates a log string with logfmt format and typed fields:
+// integers, floats, durations, booleans and quoted strings. The level
+// follows the status code.
+func (g *Generator) NewLogfmtLog(t time.Time, URI string, statusCode int) string {
+	level := "info"
+	if statusCode >= 500 {
+		level = "error"
+	} else if statusCode >= 400 {
+		level = "warn"
+	}
 	return fmt.Sprintf(
-		ShoppingCartLogFormat,
-		gofakeit.Number(1, 10000),
-		gofakeit.UUID(),
-		gofakeit.Price(10.0, 1000.0),
-		gofakeit.CreditCardType(),
-		RandShippingMethod(),
-		gofakeit.CountryAbr(),
+		LogfmtLog,
+		t.Format(time.RFC3339Nano),
+		level,
+		g.HackerPhrase(),
+		g.HTTPMethod(),
+		logfmtValue(URI),
+		statusCode,
+		time.Duration(g.Number(100, 5000000))*time.Microsecond,
+		g.Number(0, 5000000),
+		g.rand.Float64(),
+		g.rand.Intn(2) == 0,
+		logfmtValue(strings.ToLower(g.Username())),
 	)
 }
 
-func NewShoppingCartWithMetadata(t time.Time) (string, push.LabelsAdapter) {
-	orderId := gofakeit.Number(1, 10000)
-	customerId := gofakeit.UUID()
-	price := gofakeit.Price(10.0, 1000.0)
-	paymentMethod := gofakeit.CreditCardType()
-	shippingMethod := RandShippingMethod()
-
-  var country string
-  if price > 700.0 { 
-    country = "US"
-  } else {
-	  country = gofakeit.CountryAbr()
-  }
+// logfmtValue returns s as a logfmt value: quoted when empty or when it has
+// spaces, quotes, '=' or control characters.
+func logfmtValue(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return r <= ' ' || r == '"' || r == '=' || r == 0x7f }) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// NewIISW3CLog creates a log string with the W3C extended log format of IIS,
+// the fields of IISW3CFields
+func NewIISW3CLog(t time.Time, URI string, statusCode int) string {
+	return global().NewIISW3CLog(t, URI, statusCode)
+}
 
+// NewIISW3CLog creates a log string with the W3C extended log format of IIS,
+// the fields of IISW3CFields
+func (g *Generator) NewIISW3CLog(t time.Time, URI string, statusCode int) string {
+	query := "-"
+	if g.rand.Intn(3) == 0 {
+		query = fmt.Sprintf("id=%d&lang=%s", g.Number(1, 10000), g.RandomString([]string{"en", "de", "fr"}))
+	}
+	username := "-"
+	if g.rand.Intn(2) == 0 {
+		username = `CORP\` + strings.ToLower(g.Username())
+	}
+	referer := "-"
+	if g.rand.Intn(2) == 0 {
+		referer = g.URL()
+	}
+	t = t.UTC()
+	return fmt.Sprintf(
+		IISW3CLog,
+		t.Format(W3CDate),
+		t.Format(W3CTime),
+		g.FakeIP(),
+		g.HTTPMethod(),
+		URI,
+		query,
+		[]int{80, 443}[g.rand.Intn(2)],
+		username,
+		g.IPv4Address(),
+		strings.ReplaceAll(g.UserAgent(), " ", "+"),
+		referer,
+		statusCode,
+		0,
+		0,
+		g.Number(1, 30000),
+	)
+}
+
+// securityDevices are the vendor, product and version of the devices
+// writing CEF and LEEF lines.
+var securityDevices = [][3]string{
+	{"Fortinet", "FortiGate", "7.2.4"},
+	{"Palo Alto Networks", "PAN-OS", "10.1.6"},
+	{"Check Point", "VPN-1 & FireWall-1", "R81.20"},
+	{"Trend Micro", "Deep Security Agent", "20.0.877"},
+}
+
+// securityEvents are the signature ids and names of CEF and LEEF events.
+var securityEvents = []struct {
+	id   int
+	name string
+	cat  string
+}{
+	{100, "Traffic allowed", "traffic"},
+	{101, "Traffic denied | policy violation", "traffic"},
+	{200, "Port scan detected", "recon"},
+	{300, "Brute force login attempt", "authentication"},
+	{400, "Malware blocked", "malware"},
+	{500, "SQL injection attempt", "intrusion"},
+}
+
+// NewCEFLog creates a log string with ArcSight Common Event Format and the
+// given severity (0 to 10)
+func NewCEFLog(t time.Time, severity int) string {
+	return global().NewCEFLog(t, severity)
+}
+
+// NewCEFLog creates a log string with ArcSight Common Event Format and the
+// given severity (0 to 10)
+func (g *Generator) NewCEFLog(t time.Time, severity int) string {
+	device := securityDevices[g.rand.Intn(len(securityDevices))]
+	event := securityEvents[g.rand.Intn(len(securityEvents))]
+	extension := strings.Join([]string{
+		"rt=" + strconv.FormatInt(t.UnixMilli(), 10),
+		"src=" + g.IPv4Address(),
+		"spt=" + strconv.Itoa(g.Number(1024, 65535)),
+		"dst=" + g.FakeIP(),
+		"dpt=" + strconv.Itoa([]int{22, 80, 443, 3306, 3389}[g.rand.Intn(5)]),
+		"proto=" + []string{"TCP", "UDP"}[g.rand.Intn(2)],
+		"act=" + []string{"allowed", "blocked", "dropped"}[g.rand.Intn(3)],
+		"suser=" + escapeCEFExtension(strings.ToLower(g.Username())),
+		"request=" + escapeCEFExtension(g.URL()+g.RandResourceURI()+"?q=a=b"),
+		"msg=" + escapeCEFExtension(g.HackerPhrase()),
+	}, " ")
+	return fmt.Sprintf(
+		CEFLog,
+		escapeCEFHeader(device[0]),
+		escapeCEFHeader(device[1]),
+		escapeCEFHeader(device[2]),
+		event.id,
+		escapeCEFHeader(event.name),
+		severity,
+		extension,
+	)
+}
+
+// escapeCEFHeader escapes '\' and '|' in a CEF header field.
+func escapeCEFHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`).Replace(value)
+}
+
+// escapeCEFExtension escapes '\', '=' and newlines in a CEF extension value.
+func escapeCEFExtension(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
+
+// NewLEEFLog creates a log string with IBM QRadar Log Event Extended Format
+// 1.0, tab separated attributes and the given severity (1 to 10)
+func NewLEEFLog(t time.Time, severity int) string {
+	return global().NewLEEFLog(t, severity)
+}
+
+// NewLEEFLog creates a log string with IBM QRadar Log Event Extended Format
+// 1.0, tab separated attributes and the given severity (1 to 10)
+func (g *Generator) NewLEEFLog(t time.Time, severity int) string {
+	device := securityDevices[g.rand.Intn(len(securityDevices))]
+	event := securityEvents[g.rand.Intn(len(securityEvents))]
+	attributes := strings.Join([]string{
+		"devTime=" + t.UTC().Format(LEEFDevTime),
+		"devTimeFormat=" + LEEFDevTimeFormat,
+		"cat=" + event.cat,
+		"sev=" + strconv.Itoa(severity),
+		"src=" + g.IPv4Address(),
+		"srcPort=" + strconv.Itoa(g.Number(1024, 65535)),
+		"dst=" + g.FakeIP(),
+		"dstPort=" + strconv.Itoa([]int{22, 80, 443, 3306, 3389}[g.rand.Intn(5)]),
+		"proto=" + []string{"TCP", "UDP"}[g.rand.Intn(2)],
+		"usrName=" + strings.ToLower(g.Username()),
+		"action=" + []string{"allowed", "blocked", "dropped"}[g.rand.Intn(3)],
+		"reason=" + event.name,
+	}, "\t")
 	return fmt.Sprintf(
-			ShoppingCartLogFormat,
-			orderId,
-			customerId,
-			price,
-			paymentMethod,
-			shippingMethod,
-			country,
-		), push.LabelsAdapter{
-			{Name: "orderId", Value: fmt.Sprintf("%d", orderId)},
-			{Name: "customerId", Value: customerId},
-			{Name: "price", Value: fmt.Sprintf("%f", price)},
-			{Name: "paymentMethod", Value: paymentMethod},
-			{Name: "shippingMethod", Value: shippingMethod},
-			{Name: "shippingCountry", Value: country},
+		LEEFLog,
+		device[0],
+		device[1],
+		device[2],
+		strconv.Itoa(event.id),
+		attributes,
+	)
+}
+
+// NewGELFLog creates a log string with Graylog Extended Log Format and the
+// given syslog severity level (0 to 7), with additional fields of several types
+func NewGELFLog(t time.Time, level int) string {
+	return global().NewGELFLog(t, level)
+}
+
+// NewGELFLog creates a log string with Graylog Extended Log Format and the
+// given syslog severity level (0 to 7), with additional fields of several types
+func (g *Generator) NewGELFLog(t time.Time, level int) string {
+	additional, _ := json.Marshal(map[string]any{
+		"_user_id":     g.Number(1, 100000),
+		"_request_uri": g.RandResourceURI(),
+		"_duration_ms": math.Round(g.rand.Float64()*500000) / 1000,
+		"_cached":      g.rand.Intn(2) == 0,
+		"_facility":    g.Word(),
+	})
+	return fmt.Sprintf(
+		GELFLog,
+		jsonString(g.DomainName()),
+		jsonString(g.HackerPhrase()),
+		jsonString(g.HackerPhrase()+"\n"+g.HackerPhrase()),
+		float64(t.UnixMilli())/1000,
+		level,
+		strings.TrimPrefix(strings.TrimSuffix(string(additional), "}"), "{"),
+	)
+}
+
+// jsonString returns s as a JSON string, without escaping HTML characters
+// like '&' in URLs.
+func jsonString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// "Order %d successfully placed, customerId: %s, price: %f, paymentMethod: %s, shippingMethod: %s, shippingCountry: %s"
+func NewShoppingCart(t time.Time) string {
+	return global().NewShoppingCart(t)
+}
+
+// "Order %d successfully placed, customerId: %s, price: %f, paymentMethod: %s, shippingMethod: %s, shippingCountry: %s"
+func (g *Generator) NewShoppingCart(t time.Time) string {
+	order := g.NewShoppingCartOrder(t)
+	return ShoppingCartLine(order.Fields())
+}
+
+func NewShoppingCartWithMetadata(t time.Time) (string, push.LabelsAdapter) {
+	return global().NewShoppingCartWithMetadata(t)
+}
+
+func (g *Generator) NewShoppingCartWithMetadata(t time.Time) (string, push.LabelsAdapter) {
+	order := g.NewCorrelatedShoppingCartOrder(t)
+	metadata := make(push.LabelsAdapter, 0, 6)
+	for _, f := range order.Fields() {
+		metadata = append(metadata, push.LabelAdapter{Name: f.Name, Value: f.Value})
+	}
+	return ShoppingCartLine(order.Fields()), metadata
+}
+
+// ShoppingCartOrder is an order placed on the shopping cart services.
+type ShoppingCartOrder struct {
+	OrderID         int
+	CustomerID      string
+	Price           float64
+	PaymentMethod   string
+	ShippingMethod  string
+	ShippingCountry string
+}
+
+// NewShoppingCartOrder returns a random order.
+func NewShoppingCartOrder(t time.Time) ShoppingCartOrder {
+	return global().NewShoppingCartOrder(t)
+}
+
+// NewShoppingCartOrder returns a random order.
+func (g *Generator) NewShoppingCartOrder(t time.Time) ShoppingCartOrder {
+	return ShoppingCartOrder{
+		OrderID:         g.Number(1, 10000),
+		CustomerID:      g.UUID(),
+		Price:           g.Price(10.0, 1000.0),
+		PaymentMethod:   g.CreditCardType(),
+		ShippingMethod:  g.RandShippingMethod(),
+		ShippingCountry: g.CountryAbr(),
+	}
+}
+
+// NewCorrelatedShoppingCartOrder returns a random order where orders over
+// 700 always ship to the US, a correlation to find with field filters.
+func NewCorrelatedShoppingCartOrder(t time.Time) ShoppingCartOrder {
+	return global().NewCorrelatedShoppingCartOrder(t)
+}
+
+// NewCorrelatedShoppingCartOrder returns a random order where orders over
+// 700 always ship to the US, a correlation to find with field filters.
+func (g *Generator) NewCorrelatedShoppingCartOrder(t time.Time) ShoppingCartOrder {
+	order := ShoppingCartOrder{
+		OrderID:        g.Number(1, 10000),
+		CustomerID:     g.UUID(),
+		Price:          g.Price(10.0, 1000.0),
+		PaymentMethod:  g.CreditCardType(),
+		ShippingMethod: g.RandShippingMethod(),
+	}
+	if order.Price > 700.0 {
+		order.ShippingCountry = "US"
+	} else {
+		order.ShippingCountry = g.CountryAbr()
+	}
+	return order
+}
+
+// Fields returns the fields of the order, in the order of ShoppingCartLogFormat.
+func (o ShoppingCartOrder) Fields() []log.Field {
+	return []log.Field{
+		{Name: "orderId", Value: fmt.Sprintf("%d", o.OrderID)},
+		{Name: "customerId", Value: o.CustomerID},
+		{Name: "price", Value: fmt.Sprintf("%f", o.Price)},
+		{Name: "paymentMethod", Value: o.PaymentMethod},
+		{Name: "shippingMethod", Value: o.ShippingMethod},
+		{Name: "shippingCountry", Value: o.ShippingCountry},
+	}
+}
+
+// ShoppingCartLine writes the order placement message with the inline
+// fields of an order. With every field of ShoppingCartOrder.Fields, it is
+// ShoppingCartLogFormat.
+func ShoppingCartLine(inline []log.Field) string {
+	var b strings.Builder
+	b.WriteString("Order ")
+	for _, f := range inline {
+		if f.Name == "orderId" {
+			b.WriteString(f.Value + " ")
+		}
+	}
+	b.WriteString("successfully placed")
+	for _, f := range inline {
+		if f.Name != "orderId" {
+			fmt.Fprintf(&b, ", %s: %s", f.Name, f.Value)
 		}
+	}
+	return b.String()
 }
@@ -0,0 +1,126 @@
+package flog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShoppingCartLine(t *testing.T) {
+	order := NewShoppingCartOrder(time.Now())
+	expected := fmt.Sprintf(ShoppingCartLogFormat, order.OrderID, order.CustomerID, order.Price, order.PaymentMethod, order.ShippingMethod, order.ShippingCountry)
+	assert.Equal(t, expected, ShoppingCartLine(order.Fields()))
+
+	assert.Equal(t, "Order successfully placed, price: 12.500000", ShoppingCartLine([]log.Field{{Name: "price", Value: "12.500000"}}))
+	assert.Equal(t, "Order successfully placed", ShoppingCartLine(nil))
+}
+
+func TestCorrelatedShoppingCartOrder(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		order := NewCorrelatedShoppingCartOrder(time.Now())
+		if order.Price > 700 {
+			assert.Equal(t, "US", order.ShippingCountry)
+		}
+	}
+}
+
+func TestRandStructuredData(t *testing.T) {
+	element := regexp.MustCompile(`^\[([^ \]"=]+)((?: [^ \]"=]+="(?:[^"\\\]]|\\["\\\]])*")*)\]`)
+	seen := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		sd := RandStructuredData()
+		if sd == "-" {
+			continue
+		}
+		ids := map[string]bool{}
+		for rest := sd; rest != ""; {
+			m := element.FindStringSubmatch(rest)
+			require.NotNil(t, m, "invalid structured data %q", sd)
+			assert.False(t, ids[m[1]], "duplicate SD-ID in %q", sd)
+			ids[m[1]] = true
+			seen[m[1]] = true
+			rest = rest[len(m[0]):]
+		}
+		assert.LessOrEqual(t, len(ids), 3)
+	}
+	assert.Len(t, seen, len(sdElements))
+}
+
+func TestNewRFC5424LogWithPriority(t *testing.T) {
+	line := NewRFC5424LogWithPriority(time.Now(), 11)
+	assert.Regexp(t, `^<11>\d `, line)
+}
+
+func TestNewLogfmtLog(t *testing.T) {
+	line := NewLogfmtLog(time.Now(), "/api/v1/push", 503)
+	assert.Regexp(t, `^ts=\S+ level=error msg="[^"]*" method=[A-Z]+ path=/api/v1/push status=503 duration=[0-9.]+[µm]?s bytes=\d+ ratio=0\.\d{3} cached=(true|false) user=\S+$`, line)
+}
+
+func TestNewIISW3CLog(t *testing.T) {
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	fields := strings.Fields(NewIISW3CLog(ts, "/default.htm", 404))
+	names := strings.Fields(strings.TrimPrefix(IISW3CFields, "#Fields: "))
+	require.Len(t, fields, len(names))
+	values := map[string]string{}
+	for i, name := range names {
+		values[name] = fields[i]
+	}
+	assert.Equal(t, "2026-04-26", values["date"])
+	assert.Equal(t, "11:00:00", values["time"])
+	assert.Equal(t, "/default.htm", values["cs-uri-stem"])
+	assert.Equal(t, "404", values["sc-status"])
+}
+
+func TestNewCEFLog(t *testing.T) {
+	unescapedPipe := regexp.MustCompile(`(^|[^\\])\|`)
+	for i := 0; i < 50; i++ {
+		line := NewCEFLog(time.Now(), 7)
+		// The header is seven fields, the extension follows the last unescaped pipe.
+		assert.Len(t, unescapedPipe.FindAllStringIndex(line, -1), 7, line)
+		assert.Contains(t, line, "|7|rt=")
+		assert.NotRegexp(t, `request=\S*[^\\]=`, line, "equal signs in extension values are escaped")
+	}
+	assert.Equal(t, `a\|b\\c`, escapeCEFHeader(`a|b\c`))
+	assert.Equal(t, `a\=b\\c\n`, escapeCEFExtension("a=b\\c\n"))
+}
+
+func TestNewLEEFLog(t *testing.T) {
+	line := NewLEEFLog(time.Now(), 4)
+	parts := strings.SplitN(line, "|", 6)
+	require.Len(t, parts, 6)
+	assert.Equal(t, "LEEF:1.0", parts[0])
+	attributes := map[string]string{}
+	for _, attr := range strings.Split(parts[5], "\t") {
+		k, v, ok := strings.Cut(attr, "=")
+		require.True(t, ok, attr)
+		attributes[k] = v
+	}
+	assert.Equal(t, "4", attributes["sev"])
+	_, err := time.Parse(LEEFDevTime, attributes["devTime"])
+	assert.NoError(t, err)
+}
+
+func TestNewGELFLog(t *testing.T) {
+	ts := time.UnixMilli(1714129200123)
+	var msg map[string]any
+	require.NoError(t, json.Unmarshal([]byte(NewGELFLog(ts, 3)), &msg))
+	assert.Equal(t, "1.1", msg["version"])
+	assert.Equal(t, 1714129200.123, msg["timestamp"])
+	assert.Equal(t, float64(3), msg["level"])
+	assert.NotEmpty(t, msg["host"])
+	assert.NotEmpty(t, msg["short_message"])
+	for key := range msg {
+		switch key {
+		case "version", "host", "short_message", "full_message", "timestamp", "level":
+		default:
+			assert.True(t, strings.HasPrefix(key, "_"), "additional field %s", key)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/explore-logs/generator/log"
 	"github.com/spf13/pflag"
 )
 
@@ -27,6 +28,11 @@ Options:
                            - rfc3164
                            - rfc5424
                            - json
+                           - logfmt
+                           - iis_w3c
+                           - cef
+                           - leef
+                           - gelf
   -o, --output string      output filename. Path-like is allowed. (default "generated.log")
   -t, --type string        log output type. available types:
                            - stdout (default)
@@ -44,11 +50,15 @@ Options:
                            with "byte" option, the logs will be split whenever the maximum size in bytes is reached.
   -w, --overwrite          overwrite the existing log files.
   -l, --loop               loop output forever until killed.
+      --seed integer       seed of the random values, to generate the same logs on every run (default: random).
+      --pools string       size and skew of the ips and resources value pools, as 'pool:option,...' entries separated by ';'.
+                           options: size=<n>, zipf, zipf=<s>, uniform
+                           example: 'ips:zipf=1.2,size=200;resources:zipf'
 `
 )
 
 var (
-	validFormats = []string{"apache_common", "apache_combined", "apache_error", "rfc3164", "rfc5424", "common_log", "json"}
+	validFormats = []string{"apache_common", "apache_combined", "apache_error", "rfc3164", "rfc5424", "common_log", "json", "logfmt", "iis_w3c", "cef", "leef", "gelf"}
 	validTypes   = []string{"stdout", "log", "gz"}
 )
 
@@ -64,10 +74,21 @@ type Option struct {
 	SplitBy   int
 	Overwrite bool
 	Forever   bool
+	// Seed seeds the random values of the logs when non-zero.
+	Seed int64
+	// Pools configures the value pools, see log.ConfigurePools.
+	Pools string
 }
 
 func init() {
 	pflag.Usage = printUsage
+	// --split was the flag's name before it matched the usage.
+	pflag.CommandLine.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "split" {
+			name = "split-by"
+		}
+		return pflag.NormalizedName(name)
+	})
 }
 
 func printUsage() {
@@ -183,9 +204,11 @@ func ParseOptions() *Option {
 	bytes := pflag.IntP("bytes", "b", opts.Bytes, "Size of logs to generate. (in bytes)")
 	sleepString := pflag.StringP("sleep", "s", "0s", "Creation time interval (default unit: seconds)")
 	delayString := pflag.StringP("delay", "d", "0s", "Log generation speed (default unit: seconds)")
-	splitBy := pflag.IntP("split", "p", opts.SplitBy, "Maximum number of lines or size of a log file")
+	splitBy := pflag.IntP("split-by", "p", opts.SplitBy, "Maximum number of lines or size of a log file")
 	overwrite := pflag.BoolP("overwrite", "w", false, "Overwrite the existing log files")
 	forever := pflag.BoolP("loop", "l", false, "Loop output forever until killed")
+	seed := pflag.Int64("seed", opts.Seed, "Seed of the random values (default: random)")
+	pools := pflag.String("pools", opts.Pools, "Size and skew of the value pools")
 
 	pflag.Parse()
 
@@ -221,5 +244,10 @@ func ParseOptions() *Option {
 	opts.Output = *output
 	opts.Overwrite = *overwrite
 	opts.Forever = *forever
+	opts.Seed = *seed
+	if _, err = log.ParsePoolConfigs(*pools); err != nil {
+		errorExit(err)
+	}
+	opts.Pools = *pools
 	return opts
 }
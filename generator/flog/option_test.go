@@ -14,6 +14,12 @@ func TestParseFormat(t *testing.T) {
 	a.Equal("apache_common", format, "format should be apache_common")
 	a.NoError(err, "there should be no error")
 
+	for _, f := range []string{"logfmt", "iis_w3c", "cef", "leef", "gelf"} {
+		format, err = ParseFormat(f)
+		a.Equal(f, format)
+		a.NoError(err)
+	}
+
 	format, err = ParseFormat("unknown")
 	a.Equal("", format, "format should be empty string when invalid format is given")
 	a.Error(err, "there should be an error when invalid format is given")
@@ -0,0 +1,442 @@
+package flog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLog parses a line of format back into its fields, named after the
+// placeholders of the format's constant (e.g. "host", "status" for
+// ApacheCommonLog). It fails when the line is not valid for format, so
+// format drift is caught before lines reach Loki.
+//
+// RFC5424 structured data params are returned as "sd.<SD-ID>.<name>", CEF
+// extensions and LEEF attributes by key, and JSON and GELF fields by key,
+// with nested objects as JSON.
+func ParseLog(format, line string) (map[string]string, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid format", format)
+	}
+	fields, err := parse(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s line %q: %w", format, line, err)
+	}
+	return fields, nil
+}
+
+var parsers = map[string]func(string) (map[string]string, error){
+	"apache_common":   parseApacheCommonLog,
+	"apache_combined": parseApacheCombinedLog,
+	"apache_error":    parseApacheErrorLog,
+	"rfc3164":         parseRFC3164Log,
+	"rfc5424":         parseRFC5424Log,
+	"common_log":      parseApacheCommonLog,
+	"json":            parseJSONLog,
+	"logfmt":          parseLogfmtLog,
+	"iis_w3c":         parseIISW3CLog,
+	"cef":             parseCEFLog,
+	"leef":            parseLEEFLog,
+	"gelf":            parseGELFLog,
+}
+
+var (
+	apacheCommonRegexp   = regexp.MustCompile(`^(\S+) - (\S+) \[([^\]]+)\] "(\S+) (\S+) (\S+)" (\d{3}) (\d+)$`)
+	apacheCombinedRegexp = regexp.MustCompile(`^(\S+) - (\S+) \[([^\]]+)\] "(\S+) (\S+) (\S+)" (\d{3}) (\d+) "([^"]*)" "([^"]*)"$`)
+	apacheErrorRegexp    = regexp.MustCompile(`^\[([^\]]+)\] \[(\S+):(\S+)\] \[pid (\d+):tid (\d+)\] \[client (\S+):(\d+)\] (.*)$`)
+	rfc3164Regexp        = regexp.MustCompile(`^<(\d{1,3})>(\w{3} \d\d \d\d:\d\d:\d\d) (\S+) (\S+)\[(\d+)\]: (.*)$`)
+	rfc5424Regexp        = regexp.MustCompile(`^<(\d{1,3})>(\d) (\S+) (\S+) (\S+) (\S+) (\S+) (.*)$`)
+)
+
+// match returns the fields named names of the submatches of re in line.
+func match(re *regexp.Regexp, line string, names ...string) (map[string]string, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("does not match %s", re)
+	}
+	fields := make(map[string]string, len(names))
+	for i, name := range names {
+		fields[name] = m[i+1]
+	}
+	return fields, nil
+}
+
+// checkTime checks that the field name of fields is a time in layout.
+func checkTime(fields map[string]string, name, layout string) error {
+	if _, err := time.Parse(layout, fields[name]); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// checkIP checks that the field name of fields is an IP address.
+func checkIP(fields map[string]string, name string) error {
+	if net.ParseIP(fields[name]) == nil {
+		return fmt.Errorf("%s: %q is not an IP address", name, fields[name])
+	}
+	return nil
+}
+
+func parseApacheCommonLog(line string) (map[string]string, error) {
+	fields, err := match(apacheCommonRegexp, line, "host", "auth-user-id", "datetime", "method", "request", "protocol", "status", "bytes")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIP(fields, "host"); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "datetime", Apache)
+}
+
+func parseApacheCombinedLog(line string) (map[string]string, error) {
+	fields, err := match(apacheCombinedRegexp, line, "host", "auth-user-id", "datetime", "method", "request", "protocol", "status", "bytes", "referrer", "agent")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIP(fields, "host"); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "datetime", Apache)
+}
+
+func parseApacheErrorLog(line string) (map[string]string, error) {
+	fields, err := match(apacheErrorRegexp, line, "timestamp", "module", "severity", "pid", "thread-id", "client", "port", "message")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIP(fields, "client"); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "timestamp", ApacheError)
+}
+
+// checkPriority checks that the priority field of fields is a valid syslog
+// priority, facility*8 + severity.
+func checkPriority(fields map[string]string) error {
+	if p, _ := strconv.Atoi(fields["priority"]); p > 191 {
+		return fmt.Errorf("priority %d is over 191", p)
+	}
+	return nil
+}
+
+func parseRFC3164Log(line string) (map[string]string, error) {
+	fields, err := match(rfc3164Regexp, line, "priority", "timestamp", "hostname", "application", "pid", "message")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPriority(fields); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "timestamp", RFC3164)
+}
+
+func parseRFC5424Log(line string) (map[string]string, error) {
+	fields, err := match(rfc5424Regexp, line, "priority", "version", "iso-timestamp", "hostname", "application", "pid", "message-id", "rest")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPriority(fields); err != nil {
+		return nil, err
+	}
+	if err := checkTime(fields, "iso-timestamp", RFC5424); err != nil {
+		return nil, err
+	}
+	rest := fields["rest"]
+	delete(fields, "rest")
+	sd, msg, err := parseStructuredData(rest, fields)
+	if err != nil {
+		return nil, err
+	}
+	fields["structured-data"] = sd
+	fields["message"] = msg
+	return fields, nil
+}
+
+// parseStructuredData parses the RFC5424 STRUCTURED-DATA at the start of s
+// into fields, as "sd.<SD-ID>.<name>". It returns the structured data and
+// the message after it.
+func parseStructuredData(s string, fields map[string]string) (sd, msg string, err error) {
+	if rest, ok := strings.CutPrefix(s, "- "); ok {
+		return "-", rest, nil
+	}
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		end := strings.IndexAny(s[i:], " ]")
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated SD-ELEMENT")
+		}
+		id := s[i+1 : i+end]
+		if id == "" {
+			return "", "", fmt.Errorf("empty SD-ID")
+		}
+		i += end
+		for s[i] == ' ' {
+			eq := strings.Index(s[i:], `="`)
+			if eq < 0 {
+				return "", "", fmt.Errorf("SD-PARAM of %s without value", id)
+			}
+			name := s[i+1 : i+eq]
+			i += eq + 2
+			var value strings.Builder
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+					i++
+				} else if s[i] == ']' {
+					return "", "", fmt.Errorf("unescaped ']' in SD-PARAM %s of %s", name, id)
+				}
+				value.WriteByte(s[i])
+			}
+			if i++; i >= len(s) {
+				return "", "", fmt.Errorf("unterminated SD-PARAM %s of %s", name, id)
+			}
+			fields["sd."+id+"."+name] = value.String()
+		}
+		if s[i] != ']' {
+			return "", "", fmt.Errorf("unterminated SD-ELEMENT %s", id)
+		}
+		i++
+	}
+	if i == 0 {
+		return "", "", fmt.Errorf("invalid STRUCTURED-DATA %q", s)
+	}
+	if i < len(s) && s[i] != ' ' {
+		return "", "", fmt.Errorf("no space after STRUCTURED-DATA")
+	}
+	return s[:i], strings.TrimPrefix(s[i:], " "), nil
+}
+
+// parseJSON parses line as a JSON object into fields: strings as is, other
+// values as JSON.
+func parseJSON(line string) (map[string]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &object); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string, len(object))
+	for k, v := range object {
+		var s string
+		if json.Unmarshal(v, &s) == nil {
+			fields[k] = s
+		} else {
+			fields[k] = string(v)
+		}
+	}
+	return fields, nil
+}
+
+// requireFields checks that fields has every field of names.
+func requireFields(fields map[string]string, names ...string) error {
+	for _, name := range names {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("missing %s", name)
+		}
+	}
+	return nil
+}
+
+func parseJSONLog(line string) (map[string]string, error) {
+	fields, err := parseJSON(line)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireFields(fields, "host", "user-identifier", "datetime", "method", "request", "protocol", "status", "bytes", "referer", "_25values", "msg", "nested_object"); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "datetime", CommonLog)
+}
+
+func parseGELFLog(line string) (map[string]string, error) {
+	fields, err := parseJSON(line)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireFields(fields, "version", "host", "short_message", "timestamp", "level"); err != nil {
+		return nil, err
+	}
+	if fields["version"] != "1.1" {
+		return nil, fmt.Errorf("version %q is not 1.1", fields["version"])
+	}
+	if _, ok := fields["_id"]; ok {
+		return nil, fmt.Errorf("additional field _id is reserved")
+	}
+	return fields, nil
+}
+
+// parseLogfmtLog parses key=value pairs separated by spaces, with values
+// quoted as Go strings when they need to.
+func parseLogfmtLog(line string) (map[string]string, error) {
+	fields := map[string]string{}
+	for rest := line; rest != ""; {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || strings.ContainsAny(rest[:eq], ` "`) {
+			return nil, fmt.Errorf("no key at %q", rest)
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexByte(rest, ' ')
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		if rest != "" {
+			if rest[0] != ' ' {
+				return nil, fmt.Errorf("%s: no space after value", key)
+			}
+			rest = rest[1:]
+		}
+		fields[key] = value
+	}
+	if err := requireFields(fields, "ts", "level", "msg", "method", "path", "status", "duration", "bytes", "ratio", "cached", "user"); err != nil {
+		return nil, err
+	}
+	return fields, checkTime(fields, "ts", time.RFC3339Nano)
+}
+
+func parseIISW3CLog(line string) (map[string]string, error) {
+	names := strings.Fields(strings.TrimPrefix(IISW3CFields, "#Fields: "))
+	values := strings.Split(line, " ")
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("%d fields instead of %d", len(values), len(names))
+	}
+	fields := make(map[string]string, len(names))
+	for i, name := range names {
+		if values[i] == "" {
+			return nil, fmt.Errorf("empty %s", name)
+		}
+		fields[name] = values[i]
+	}
+	if _, err := time.Parse(W3CDate+" "+W3CTime, fields["date"]+" "+fields["time"]); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// splitEscaped splits s at the first n unescaped sep, unescaping '\\' and
+// sep in the parts before the last.
+func splitEscaped(s string, sep byte, n int) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	i := 0
+	for ; i < len(s) && len(parts) < n; i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == sep):
+			i++
+			part.WriteByte(s[i])
+		case s[i] == sep:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(s[i])
+		}
+	}
+	if len(parts) < n {
+		return nil, fmt.Errorf("%d '%c' separated fields instead of %d", len(parts)+1, sep, n+1)
+	}
+	return append(parts, s[i:]), nil
+}
+
+func parseCEFLog(line string) (map[string]string, error) {
+	rest, ok := strings.CutPrefix(line, "CEF:")
+	if !ok {
+		return nil, fmt.Errorf("no CEF: prefix")
+	}
+	header, err := splitEscaped(rest, '|', 7)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for i, name := range []string{"version", "vendor", "product", "device-version", "signature-id", "name", "severity"} {
+		fields[name] = header[i]
+	}
+	if severity, err := strconv.Atoi(fields["severity"]); err != nil || severity < 0 || severity > 10 {
+		return nil, fmt.Errorf("severity %q is not between 0 and 10", fields["severity"])
+	}
+	return fields, parseCEFExtension(header[7], fields)
+}
+
+// cefKeyRegexp matches the keys of CEF extensions, after a space or at the start.
+var cefKeyRegexp = regexp.MustCompile(`(?:^| )(\w+)=`)
+
+// parseCEFExtension parses the key=value pairs of a CEF extension into
+// fields. Values end at the next unescaped key=.
+func parseCEFExtension(extension string, fields map[string]string) error {
+	// Escaped '=' never follow a key, so every match is a key.
+	keys := cefKeyRegexp.FindAllStringSubmatchIndex(extension, -1)
+	if len(keys) == 0 || keys[0][0] != 0 {
+		return fmt.Errorf("extension %q does not start with a key", extension)
+	}
+	for i, m := range keys {
+		end := len(extension)
+		if i+1 < len(keys) {
+			end = keys[i+1][0]
+		}
+		value := extension[m[1]:end]
+		var b strings.Builder
+		for j := 0; j < len(value); j++ {
+			if value[j] != '\\' {
+				if value[j] == '=' {
+					return fmt.Errorf("unescaped '=' in %s", extension[m[2]:m[3]])
+				}
+				b.WriteByte(value[j])
+				continue
+			}
+			if j++; j == len(value) {
+				return fmt.Errorf("trailing '\\' in %s", extension[m[2]:m[3]])
+			}
+			switch value[j] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(value[j])
+			}
+		}
+		fields[extension[m[2]:m[3]]] = b.String()
+	}
+	return nil
+}
+
+func parseLEEFLog(line string) (map[string]string, error) {
+	rest, ok := strings.CutPrefix(line, "LEEF:")
+	if !ok {
+		return nil, fmt.Errorf("no LEEF: prefix")
+	}
+	header := strings.SplitN(rest, "|", 6)
+	if len(header) != 6 {
+		return nil, fmt.Errorf("%d '|' separated fields instead of 6", len(header))
+	}
+	fields := map[string]string{}
+	for i, name := range []string{"version", "vendor", "product", "device-version", "event-id"} {
+		fields[name] = header[i]
+	}
+	for _, attribute := range strings.Split(header[5], "\t") {
+		key, value, ok := strings.Cut(attribute, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("attribute %q is not key=value", attribute)
+		}
+		fields[key] = value
+	}
+	if format, ok := fields["devTimeFormat"]; ok && format == LEEFDevTimeFormat {
+		if err := checkTime(fields, "devTime", LEEFDevTime); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,256 @@
+package flog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linesPerFormat is the number of lines generated per format by the round
+// trip tests.
+const linesPerFormat = 2000
+
+// hostileURIs have the characters that break formats written with Sprintf.
+var hostileURIs = []string{
+	`/search?q="quoted"&lang=en`,
+	`/files/C:\temp\log`,
+	`/path with spaces/a=b`,
+	"/tab\there",
+	`/unicode/日本語`,
+}
+
+// roundTrip is the input of one generated line.
+type roundTrip struct {
+	g      *Generator
+	t      time.Time
+	uri    string
+	status int
+	level  int
+}
+
+// roundTrips returns the inputs of linesPerFormat lines of a format, drawn
+// from seed. With hostile, some URIs are hostileURIs.
+func roundTrips(seed int64, hostile bool) []roundTrip {
+	r := rand.New(rand.NewSource(seed))
+	g := NewGenerator(rand.New(rand.NewSource(seed)))
+	zones := []*time.Location{time.UTC, time.FixedZone("IST", 5*3600+1800), time.FixedZone("PST", -8*3600)}
+	inputs := make([]roundTrip, linesPerFormat)
+	for i := range inputs {
+		uri := log.RandURI()
+		if hostile && i%4 == 0 {
+			uri = hostileURIs[r.Intn(len(hostileURIs))]
+		} else if i%2 == 0 {
+			uri = g.RandResourceURI()
+		}
+		inputs[i] = roundTrip{
+			g:      g,
+			t:      time.Unix(1600000000+r.Int63n(300000000), r.Int63n(1e9)).In(zones[r.Intn(len(zones))]),
+			uri:    uri,
+			status: g.HTTPStatusCode(),
+			level:  r.Intn(8),
+		}
+	}
+	return inputs
+}
+
+func TestParseLogRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		format  string
+		hostile bool
+		line    func(in roundTrip) string
+		check   func(t *testing.T, in roundTrip, line string, fields map[string]string)
+	}{
+		{
+			format: "apache_common",
+			line:   func(in roundTrip) string { return in.g.NewApacheCommonLog(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(Apache), f["datetime"])
+				assert.Equal(t, in.uri, f["request"])
+				assert.Equal(t, strconv.Itoa(in.status), f["status"])
+				assert.Equal(t, line, fmt.Sprintf(strings.ReplaceAll(ApacheCommonLog, "%d", "%s"), f["host"], f["auth-user-id"], f["datetime"], f["method"], f["request"], f["protocol"], f["status"], f["bytes"]))
+			},
+		},
+		{
+			format: "apache_combined",
+			line:   func(in roundTrip) string { return in.g.NewApacheCombinedLog(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(Apache), f["datetime"])
+				assert.Equal(t, in.uri, f["request"])
+				assert.Equal(t, strconv.Itoa(in.status), f["status"])
+				assert.NotContains(t, f["referrer"], " ")
+				assert.Equal(t, line, fmt.Sprintf(strings.ReplaceAll(ApacheCombinedLog, "%d", "%s"), f["host"], f["auth-user-id"], f["datetime"], f["method"], f["request"], f["protocol"], f["status"], f["bytes"], f["referrer"], f["agent"]))
+			},
+		},
+		{
+			format: "common_log",
+			line:   func(in roundTrip) string { return in.g.NewCommonLogFormat(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(CommonLog), f["datetime"])
+				assert.Equal(t, in.uri, f["request"])
+				assert.Equal(t, strconv.Itoa(in.status), f["status"])
+			},
+		},
+		{
+			format: "apache_error",
+			line:   func(in roundTrip) string { return in.g.NewApacheErrorLogWithSeverity(in.t, apacheSeverities[in.level]) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(ApacheError), f["timestamp"])
+				assert.Equal(t, apacheSeverities[in.level], f["severity"])
+				assert.NotEmpty(t, f["message"])
+			},
+		},
+		{
+			format: "rfc3164",
+			line:   func(in roundTrip) string { return in.g.NewRFC3164LogWithPriority(in.t, 8+in.level) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, strconv.Itoa(8+in.level), f["priority"])
+				assert.Equal(t, in.t.Format(RFC3164), f["timestamp"])
+				assert.NotEmpty(t, f["message"])
+			},
+		},
+		{
+			format: "rfc5424",
+			line:   func(in roundTrip) string { return in.g.NewRFC5424LogWithPriority(in.t, 16*8+in.level) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, strconv.Itoa(16*8+in.level), f["priority"])
+				ts, err := time.Parse(RFC5424, f["iso-timestamp"])
+				require.NoError(t, err)
+				assert.True(t, in.t.Truncate(time.Millisecond).Equal(ts), "%s is the time of %s", f["iso-timestamp"], in.t)
+				if ip, ok := f["sd.origin.ip"]; ok {
+					assert.NotNil(t, net.ParseIP(ip))
+				}
+				if msg, ok := f["sd.quote@53595.msg"]; ok {
+					assert.Equal(t, `said "hello" [world] C:\temp`, msg)
+				}
+				assert.NotEmpty(t, f["message"])
+			},
+		},
+		{
+			format:  "json",
+			hostile: true,
+			line:    func(in roundTrip) string { return in.g.NewJSONLogFormat(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(CommonLog), f["datetime"])
+				assert.Equal(t, in.uri, f["request"])
+				assert.Equal(t, strconv.Itoa(in.status), f["status"])
+				assert.True(t, json.Valid([]byte(f["nested_object"])))
+			},
+		},
+		{
+			format:  "logfmt",
+			hostile: true,
+			line:    func(in roundTrip) string { return in.g.NewLogfmtLog(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.Format(time.RFC3339Nano), f["ts"])
+				assert.Equal(t, in.uri, f["path"])
+				assert.Equal(t, strconv.Itoa(in.status), f["status"])
+				_, err := time.ParseDuration(f["duration"])
+				assert.NoError(t, err)
+				_, err = strconv.ParseBool(f["cached"])
+				assert.NoError(t, err)
+			},
+		},
+		{
+			format: "iis_w3c",
+			line:   func(in roundTrip) string { return in.g.NewIISW3CLog(in.t, in.uri, in.status) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, in.t.UTC().Format(W3CDate), f["date"])
+				assert.Equal(t, in.t.UTC().Format(W3CTime), f["time"])
+				assert.Equal(t, in.uri, f["cs-uri-stem"])
+				assert.Equal(t, strconv.Itoa(in.status), f["sc-status"])
+			},
+		},
+		{
+			format: "cef",
+			line:   func(in roundTrip) string { return in.g.NewCEFLog(in.t, in.level) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, strconv.Itoa(in.level), f["severity"])
+				assert.Equal(t, strconv.FormatInt(in.t.UnixMilli(), 10), f["rt"])
+				assert.True(t, strings.HasSuffix(f["request"], "?q=a=b"), f["request"])
+				assert.NotNil(t, net.ParseIP(f["src"]))
+			},
+		},
+		{
+			format: "leef",
+			line:   func(in roundTrip) string { return in.g.NewLEEFLog(in.t, in.level+1) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, strconv.Itoa(in.level+1), f["sev"])
+				assert.Equal(t, in.t.UTC().Format(LEEFDevTime), f["devTime"])
+				assert.NotNil(t, net.ParseIP(f["src"]))
+			},
+		},
+		{
+			format: "gelf",
+			line:   func(in roundTrip) string { return in.g.NewGELFLog(in.t, in.level) },
+			check: func(t *testing.T, in roundTrip, line string, f map[string]string) {
+				assert.Equal(t, strconv.Itoa(in.level), f["level"])
+				assert.Equal(t, fmt.Sprintf("%.3f", float64(in.t.UnixMilli())/1000), f["timestamp"])
+				assert.Contains(t, f["full_message"], "\n")
+			},
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			for i, in := range roundTrips(int64(len(tc.format)), tc.hostile) {
+				line := tc.line(in)
+				fields, err := ParseLog(tc.format, line)
+				require.NoError(t, err, "line %d", i)
+				tc.check(t, in, line, fields)
+				if t.Failed() {
+					t.Fatalf("line %d: %s", i, line)
+				}
+			}
+		})
+	}
+}
+
+// apacheSeverities are Apache error log severities by syslog severity.
+var apacheSeverities = []string{"emerg", "alert", "crit", "error", "warn", "notice", "info", "debug"}
+
+func TestParseLogEveryFormat(t *testing.T) {
+	for _, format := range validFormats {
+		_, err := ParseLog(format, NewLog(format, time.Now()))
+		assert.NoError(t, err, format)
+	}
+	_, err := ParseLog("unknown", "")
+	assert.Error(t, err)
+}
+
+func TestParseLogInvalid(t *testing.T) {
+	for format, line := range map[string]string{
+		"apache_common": `10.0.0.1 - - [26/Apr/2026:11:00:00 +0000] "GET /a HTTP/1.1" 200`,
+		"rfc5424":       `<14>1 2026-04-26T11:00:00.000Z host app 1 ID1 [meta a="b] msg`,
+		"json":          `{"host":"a"b"}`,
+		"logfmt":        `ts=2026-04-26T11:00:00Z level=info msg="unterminated`,
+		"iis_w3c":       `2026-04-26 11:00:00 10.0.0.1 GET /a - 80`,
+		"cef":           `CEF:0|vendor|product|1.0|100|name|11|src=10.0.0.1`,
+		"leef":          `LEEF:1.0|vendor|product`,
+		"gelf":          `{"version":"1.0","host":"a","short_message":"b","timestamp":1,"level":1}`,
+	} {
+		_, err := ParseLog(format, line)
+		assert.Error(t, err, format)
+	}
+}
+
+func TestParseStructuredData(t *testing.T) {
+	fields := map[string]string{}
+	sd, msg, err := parseStructuredData(`[a@1 x="1" y="q\"\]\\"][b] message [not sd]`, fields)
+	require.NoError(t, err)
+	assert.Equal(t, `[a@1 x="1" y="q\"\]\\"][b]`, sd)
+	assert.Equal(t, "message [not sd]", msg)
+	assert.Equal(t, map[string]string{"sd.a@1.x": "1", "sd.a@1.y": `q"]\`}, fields)
+}
+
+func TestParseCEFExtension(t *testing.T) {
+	fields := map[string]string{}
+	require.NoError(t, parseCEFExtension(`msg=a b\=c d\\ request=http://x/?q\=1 cs1=line\nbreak`, fields))
+	assert.Equal(t, map[string]string{"msg": `a b=c d\`, "request": "http://x/?q=1", "cs1": "line\nbreak"}, fields)
+}
@@ -1,49 +1,75 @@
 package flog
 
 import (
-	"math/rand"
 	"net/url"
 	"strings"
-
-	"github.com/brianvoe/gofakeit"
 )
 
-var ressourceURIs []string
-
-func init() {
-	for i := 0; i < 20; i++ {
-		ressourceURIs = append(ressourceURIs, randResourceURI())
-	}
-}
-
 // RandResourceURI generates a random resource URI
 func RandResourceURI() string {
-	return ressourceURIs[rand.Intn(len(ressourceURIs))]
+	return global().RandResourceURI()
 }
 
-func randResourceURI() string {
+// RandResourceURI returns one of the resource URIs of the generator, 20
+// unless the log.PoolResources pool is configured otherwise.
+func (g *Generator) RandResourceURI() string {
+	return g.resourceURIs.Pick(g.rand)
+}
+
+func (g *Generator) randResourceURI() string {
 	var uri string
-	num := gofakeit.Number(1, 4)
+	num := g.Number(1, 4)
 	for i := 0; i < num; i++ {
-		uri += "/" + url.QueryEscape(gofakeit.BS())
+		uri += "/" + url.QueryEscape(g.BS())
 	}
 	uri = strings.ToLower(uri)
 	return uri
 }
 
+// FakeIP returns one of a few IP addresses, for lines repeating the same hosts.
+func FakeIP() string {
+	return global().FakeIP()
+}
+
+// FakeIP returns one of the IP addresses of the generator, 5 unless the
+// log.PoolIPs pool is configured otherwise.
+func (g *Generator) FakeIP() string {
+	return g.ips.Pick(g.rand)
+}
+
 // RandAuthUserID generates a random auth user id
 func RandAuthUserID() string {
-	candidates := []string{"-", strings.ToLower(gofakeit.Username())}
-	return candidates[rand.Intn(2)]
+	return global().RandAuthUserID()
+}
+
+// RandAuthUserID generates a random auth user id
+func (g *Generator) RandAuthUserID() string {
+	candidates := []string{"-", strings.ToLower(g.Username())}
+	return candidates[g.rand.Intn(2)]
 }
 
 // RandHTTPVersion returns a random http version
 func RandHTTPVersion() string {
+	return global().RandHTTPVersion()
+}
+
+// RandHTTPVersion returns a random http version
+func (g *Generator) RandHTTPVersion() string {
 	versions := []string{"HTTP/1.0", "HTTP/1.1", "HTTP/2.0"}
-	return versions[rand.Intn(3)]
+	return versions[g.rand.Intn(3)]
 }
 
 func RandShippingMethod() string {
+	return global().RandShippingMethod()
+}
+
+func (g *Generator) RandShippingMethod() string {
 	versions := []string{"express", "ground", "air", "2-day", "next-day"}
-	return versions[rand.Intn(5)]
+	return versions[g.rand.Intn(5)]
+}
+
+// Username returns a random user name. Unlike gofakeit's, it never has
+// spaces, which some first and last names have.
+func (g *Generator) Username() string {
+	return strings.ReplaceAll(g.Faker.Username(), " ", "")
 }
@@ -0,0 +1,223 @@
+package flog
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// timestampLayouts maps the layout names accepted by the template `ts`
+// function to Go time layouts. Any other argument is used as a layout as-is.
+var timestampLayouts = map[string]string{
+	"apache":       Apache,
+	"apache_error": ApacheError,
+	"rfc3164":      RFC3164,
+	"rfc5424":      RFC5424,
+	"common_log":   CommonLog,
+	"rfc3339":      time.RFC3339,
+	"rfc3339nano":  time.RFC3339Nano,
+	"iso8601":      "2006-01-02T15:04:05.000Z07:00",
+	"kitchen":      time.Kitchen,
+}
+
+// TemplateData is the value passed as `.` when a Template is executed.
+type TemplateData struct {
+	Time  time.Time
+	Level string
+}
+
+// Template renders log lines from a text/template definition, so new log
+// formats can be described as data instead of Sprintf constants, e.g.
+//
+//	{{ipv4}} - {{user}} [{{ts "apache"}}] "{{method}} {{httpVersion}}" {{status}} {{number 0 30000}}
+//
+// See templateFuncs for the available functions.
+type Template struct {
+	tpl *template.Template
+	// funcs are the functions added by the caller of NewTemplate.
+	funcs template.FuncMap
+
+	mu     sync.Mutex
+	global *Renderer
+}
+
+// NewTemplate parses text into a Template. funcs add functions to, or
+// replace, the functions of templateFuncs.
+func NewTemplate(name, text string, funcs ...template.FuncMap) (*Template, error) {
+	t := &Template{funcs: template.FuncMap{}}
+	for _, f := range funcs {
+		for name, fn := range f {
+			t.funcs[name] = fn
+		}
+	}
+	// Parsing only needs the names of the functions.
+	var r Renderer
+	tpl, err := template.New(name).Funcs(templateFuncs(&Generator{})).Funcs(r.timeFuncs()).Funcs(t.funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.tpl = tpl
+	return t, nil
+}
+
+// MustTemplate is like NewTemplate but panics if the template cannot be parsed.
+func MustTemplate(name, text string, funcs ...template.FuncMap) *Template {
+	t, err := NewTemplate(name, text, funcs...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute renders one line for an entry at (virtual) time t with the given
+// level, drawing its random values from the math/rand global source.
+func (t *Template) Execute(ts time.Time, level string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.global == nil {
+		t.global = t.Renderer(global())
+	}
+	return t.global.Execute(ts, level)
+}
+
+// Renderer returns a Renderer of t drawing the random values of its lines
+// from g.
+func (t *Template) Renderer(g *Generator) *Renderer {
+	r := &Renderer{}
+	// Cloning only fails once t.tpl has been executed, which it never is.
+	r.tpl = template.Must(t.tpl.Clone()).Funcs(templateFuncs(g)).Funcs(r.timeFuncs()).Funcs(t.funcs)
+	return r
+}
+
+// Renderer renders the lines of a Template with the random values of a
+// Generator. Its functions are bound once, to the entry being rendered, so
+// rendering a line only executes the template. Like its Generator, a
+// Renderer is not safe for concurrent use.
+type Renderer struct {
+	tpl  *template.Template
+	data TemplateData
+	buf  bytes.Buffer
+}
+
+// Execute renders one line for an entry at (virtual) time ts with the given
+// level.
+func (r *Renderer) Execute(ts time.Time, level string) (string, error) {
+	r.data = TemplateData{Time: ts, Level: level}
+	r.buf.Reset()
+	if err := r.tpl.Execute(&r.buf, r.data); err != nil {
+		return "", err
+	}
+	return r.buf.String(), nil
+}
+
+// timeFuncs returns the functions that depend on the entry being rendered.
+func (r *Renderer) timeFuncs() template.FuncMap {
+	return template.FuncMap{
+		// ts formats the entry timestamp with a named layout (see
+		// timestampLayouts), "unix", "unix_ms", "unix_ns" or a Go layout.
+		"ts": func(layout ...string) string {
+			if len(layout) == 0 {
+				return r.data.Time.Format(time.RFC3339Nano)
+			}
+			switch layout[0] {
+			case "unix":
+				return strconv.FormatInt(r.data.Time.Unix(), 10)
+			case "unix_ms":
+				return strconv.FormatInt(r.data.Time.UnixMilli(), 10)
+			case "unix_ns":
+				return strconv.FormatInt(r.data.Time.UnixNano(), 10)
+			}
+			if l, ok := timestampLayouts[layout[0]]; ok {
+				return r.data.Time.Format(l)
+			}
+			return r.data.Time.Format(layout[0])
+		},
+		"level": func() string { return r.data.Level },
+	}
+}
+
+func templateFuncs(g *Generator) template.FuncMap {
+	return template.FuncMap{
+		// fake values
+		"ipv4":        g.IPv4Address,
+		"ipv6":        g.IPv6Address,
+		"mac":         g.MacAddress,
+		"user":        func() string { return strings.ToLower(g.Username()) },
+		"method":      g.HTTPMethod,
+		"url":         g.URL,
+		"domain":      g.DomainName,
+		"userAgent":   g.UserAgent,
+		"word":        g.Word,
+		"phrase":      g.HackerPhrase,
+		"uuid":        g.UUID,
+		"country":     g.CountryAbr,
+		"creditCard":  g.CreditCardType,
+		"status":      g.HTTPStatusCode,
+		"fakeLevel":   g.LogLevel,
+		"number":      g.Number,
+		"price":       g.Price,
+		"fake":        func(name string) (string, error) { return g.Generate("{" + name + "}") },
+		"authUser":    g.RandAuthUserID,
+		"httpVersion": g.RandHTTPVersion,
+		"resource":    g.RandResourceURI,
+		"fakeIP":      g.FakeIP,
+
+		// choices
+		"choice":   g.templateChoice,
+		"weighted": g.templateWeighted,
+
+		// numeric distributions
+		"uniform":     func(min, max float64) float64 { return min + g.rand.Float64()*(max-min) },
+		"normal":      func(mean, stddev float64) float64 { return mean + g.rand.NormFloat64()*stddev },
+		"exponential": func(mean float64) float64 { return g.rand.ExpFloat64() * mean },
+		"lognormal":   func(mu, sigma float64) float64 { return math.Exp(mu + g.rand.NormFloat64()*sigma) },
+		"round": func(v float64, places int) float64 {
+			p := math.Pow(10, float64(places))
+			return math.Round(v*p) / p
+		},
+		"int": func(v float64) int { return int(v) },
+	}
+}
+
+// templateChoice returns one of values picked uniformly.
+func (g *Generator) templateChoice(values ...string) (string, error) {
+	if len(values) == 0 {
+		return "", fmt.Errorf("choice: no values given")
+	}
+	return values[g.rand.Intn(len(values))], nil
+}
+
+// templateWeighted picks from value/weight pairs, e.g. weighted "GET" 10 "POST" 2.
+func (g *Generator) templateWeighted(pairs ...any) (string, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return "", fmt.Errorf("weighted: expected value/weight pairs, got %d arguments", len(pairs))
+	}
+	values := make([]string, 0, len(pairs)/2)
+	weights := make([]int, 0, len(pairs)/2)
+	total := 0
+	for i := 0; i < len(pairs); i += 2 {
+		w, ok := pairs[i+1].(int)
+		if !ok || w < 0 {
+			return "", fmt.Errorf("weighted: weight for %v must be a non-negative integer", pairs[i])
+		}
+		values = append(values, fmt.Sprint(pairs[i]))
+		weights = append(weights, w)
+		total += w
+	}
+	if total == 0 {
+		return "", fmt.Errorf("weighted: weights sum to zero")
+	}
+	r := g.rand.Intn(total)
+	for i, w := range weights {
+		r -= w
+		if r < 0 {
+			return values[i], nil
+		}
+	}
+	return values[len(values)-1], nil
+}
@@ -0,0 +1,80 @@
+package flog
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateExecute(t *testing.T) {
+	a := assert.New(t)
+
+	uri := template.FuncMap{"uri": func() string { return "/api/users" }}
+	tpl, err := NewTemplate("apache", `{{ipv4}} - {{user}} [{{ts "apache"}}] "{{method}} {{uri}} {{httpVersion}}" {{.Level}} {{number 0 10}}`, uri)
+	a.NoError(err, "template should parse")
+
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	line, err := tpl.Execute(ts, "info")
+	a.NoError(err, "template should execute")
+	a.Regexp(regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+ - \S+ \[26/Apr/2026:11:00:00 \+0000\] "[A-Z]+ /api/\S+ HTTP/\d\.\d" info \d+$`), line)
+}
+
+func TestTemplateTimestampLayouts(t *testing.T) {
+	a := assert.New(t)
+
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	for layout, expected := range map[string]string{
+		`{{ts}}`:                    "2026-04-26T11:00:00Z",
+		`{{ts "rfc5424"}}`:          "2026-04-26T11:00:00.000Z",
+		`{{ts "unix"}}`:             "1777201200",
+		`{{ts "2006/01/02 15:04"}}`: "2026/04/26 11:00",
+	} {
+		line, err := MustTemplate("ts", layout).Execute(ts, "info")
+		a.NoError(err)
+		a.Equal(expected, line, layout)
+	}
+}
+
+func TestTemplateWeighted(t *testing.T) {
+	a := assert.New(t)
+
+	tpl := MustTemplate("weighted", `{{weighted "always" 1 "never" 0}}`)
+	for i := 0; i < 100; i++ {
+		line, err := tpl.Execute(time.Now(), "info")
+		a.NoError(err)
+		a.Equal("always", line)
+	}
+
+	_, err := MustTemplate("odd", `{{weighted "a" 1 "b"}}`).Execute(time.Now(), "info")
+	a.Error(err, "odd number of weighted arguments should fail")
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	a := assert.New(t)
+
+	r := MustTemplate("renderer", `{{ts "unix"}} {{level}} {{.Level}}`).Renderer(NewGenerator(rand.New(rand.NewSource(1))))
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	for i, level := range []string{"info", "error"} {
+		line, err := r.Execute(ts.Add(time.Duration(i)*time.Second), level)
+		a.NoError(err)
+		a.Equal(fmt.Sprintf("%d %s %s", ts.Unix()+int64(i), level, level), line, "functions are bound to the line being rendered")
+	}
+}
+
+func TestTemplateFake(t *testing.T) {
+	a := assert.New(t)
+
+	line, err := MustTemplate("fake", `{{fake "lastname"}} {{fake "number:1,3"}}`).Execute(time.Now(), "info")
+	a.NoError(err)
+	a.Regexp(`^.+ [123]$`, line)
+}
+
+func TestNewTemplateInvalid(t *testing.T) {
+	_, err := NewTemplate("invalid", `{{unknownFunc}}`)
+	assert.Error(t, err, "unknown functions should fail to parse")
+}
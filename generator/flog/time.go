@@ -7,4 +7,10 @@ const (
 	RFC3164     = "Jan 02 15:04:05"
 	RFC5424     = "2006-01-02T15:04:05.000Z"
 	CommonLog   = "02/Jan/2006:15:04:05 -0700"
+	W3CDate     = "2006-01-02"
+	W3CTime     = "15:04:05"
+	LEEFDevTime = "Jan 02 2006 15:04:05"
+	// LEEFDevTimeFormat is LEEFDevTime in the Java SimpleDateFormat of the
+	// LEEF devTimeFormat attribute.
+	LEEFDevTimeFormat = "MMM dd yyyy HH:mm:ss"
 )
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/flog"
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/explore-logs/generator/trace"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// requestFlows are the call graphs simulated with -flows. Every request
+// enters at nginx and logs a line in each service it goes through, all with
+// the same trace ID.
+var requestFlows = []log.Flow{
+	{
+		Name: "checkout",
+		Root: log.FlowHop{
+			Namespace: "gateway", Service: "nginx", Operation: "POST /checkout",
+			Calls: []log.FlowHop{{
+				Namespace: "e-commerce", Service: "shopping-cart-otel", Operation: "PlaceOrder",
+				Calls: []log.FlowHop{{Namespace: "checkout", Service: "payment-gateway", Operation: "Charge"}},
+			}},
+		},
+	},
+	{
+		Name: "loki-query",
+		Root: log.FlowHop{
+			Namespace: "gateway", Service: "nginx", Operation: "GET /loki/api/v1/query_range",
+			Calls: []log.FlowHop{{
+				Namespace: "loki-otel", Service: "loki-queryfrontend-otel", Operation: "loki.Query/QueryRange",
+				Calls: []log.FlowHop{{Namespace: "loki-otel", Service: "loki-querier-otel", Operation: "loki.Querier/Query", Count: 3}},
+			}},
+		},
+	},
+	{
+		Name: "mimir-push",
+		Root: log.FlowHop{
+			Namespace: "gateway", Service: "nginx", Operation: "POST /api/v1/push",
+			Calls: []log.FlowHop{{
+				Namespace: "mimir-dev", Service: "mimir-distributor", Operation: "cortex.Distributor/Push",
+				// Replication factor 3.
+				Calls: []log.FlowHop{{Namespace: "mimir-dev", Service: "mimir-ingester", Operation: "cortex.Ingester/Push", Count: 3}},
+			}},
+		},
+	},
+}
+
+// startFlows schedules the request flows whose services all match selector.
+// Lines are written by the loggers of flowLogger.
+func startFlows(sink log.Logger, emitter *trace.Emitter, useOtel, podLabel bool, selector *serviceSelector) {
+	for _, flow := range requestFlows {
+		matches := true
+		for _, svc := range flow.Services() {
+			matches = matches && selector.Matches(svc[0], svc[1])
+		}
+		if !matches {
+			continue
+		}
+		log.NewFlowSimulator(flow, emitter, flowLine, flowLogger(sink, useOtel, podLabel)).Start()
+	}
+}
+
+// flowLogger returns the loggers of the pods of request flows. Lines are
+// written to sink, or to the OTel collector for OTel services, without
+// TraceAwareLogger: the flows emit their own parent and child spans.
+// Services registered with noMetadata drop the structured metadata of their
+// lines and, with podLabel, carry their pod as a label instead.
+func flowLogger(sink log.Logger, useOtel, podLabel bool) func(pod *log.Pod) *log.AppLogger {
+	return func(pod *log.Pod) *log.AppLogger {
+		if isOtelService(pod.Service) {
+			if !useOtel {
+				return nil
+			}
+			return log.NewAppLogger(pod.Labels(), log.NewOtelLogger(string(pod.Service), pod.Labels()))
+		}
+		labels := pod.Labels()
+		svc, ok := lookupService(pod.Namespace, pod.Service)
+		if !ok || !svc.noMetadata {
+			return log.NewAppLogger(labels, sink)
+		}
+		if podLabel {
+			labels["pod"] = model.LabelValue(pod.Name)
+		}
+		return log.NewAppLogger(labels, log.LoggerFunc(func(labels model.LabelSet, ts time.Time, message string, metadata push.LabelsAdapter) error {
+			return sink.HandleWithMetadata(labels, ts, message, svc.streamMetadata(metadata))
+		}))
+	}
+}
+
+// flowLine renders the lines of a request flow: an access log line when the
+// request leaves nginx, and logfmt lines when it enters and leaves other services.
+func flowLine(hop log.FlowHop, pod *log.Pod, t time.Time, event log.FlowEvent) (model.LabelValue, string) {
+	level := log.INFO
+	switch {
+	case event.Status >= 500:
+		level = log.ERROR
+	case !event.Done:
+		level = log.DEBUG
+	}
+	if hop.Service == "nginx" {
+		if !event.Done {
+			return "", ""
+		}
+		method, path, _ := strings.Cut(hop.Operation, " ")
+		return level, fmt.Sprintf("%s trace_id=%s", flog.NewCommonLogFormatRequest(t, method, path, event.Status), event.TraceID)
+	}
+	if !event.Done {
+		return level, fmt.Sprintf(`level=%s ts=%s caller=server.go:203 msg="request received" operation=%q traceID=%s spanID=%s`, level, t.Format(time.RFC3339Nano), hop.Operation, event.TraceID, event.SpanID)
+	}
+	return level, fmt.Sprintf(`level=%s ts=%s caller=server.go:231 msg="request completed" operation=%q status=%d duration=%s traceID=%s spanID=%s`, level, t.Format(time.RFC3339Nano), hop.Operation, event.Status, event.Duration, event.TraceID, event.SpanID)
+}
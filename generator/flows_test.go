@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowsFollowServiceMetadataRules(t *testing.T) {
+	type entry struct {
+		labels   model.LabelSet
+		metadata push.LabelsAdapter
+	}
+	var entries []entry
+	capture := log.LoggerFunc(func(labels model.LabelSet, _ time.Time, _ string, metadata push.LabelsAdapter) error {
+		entries = append(entries, entry{labels, metadata})
+		return nil
+	})
+
+	nginx, ok := lookupService("gateway", "nginx")
+	require.True(t, ok)
+	require.True(t, nginx.noMetadata)
+
+	sim := log.NewFlowSimulator(requestFlows[2], nil, flowLine, flowLogger(capture, false, true))
+	traceID := sim.Request(time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC))
+	require.NotEmpty(t, entries)
+	// nginx logs its access line when the request completes.
+	assert.Equal(t, model.LabelValue("nginx"), entries[len(entries)-1].labels["service_name"])
+
+	for _, e := range entries {
+		if e.labels["service_name"] == "nginx" {
+			assert.Empty(t, e.metadata, "nginx is registered without metadata")
+			assert.NotEmpty(t, e.labels["pod"])
+			continue
+		}
+		assert.Equal(t, traceID, log.MetadataValue(e.metadata, "traceID"))
+		assert.NotContains(t, e.labels, model.LabelName("pod"))
+	}
+}
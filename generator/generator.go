@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"slices"
 	"time"
 
-	"github.com/brianvoe/gofakeit"
 	"github.com/grafana/explore-logs/generator/flog"
 	"github.com/grafana/explore-logs/generator/log"
 	"github.com/grafana/loki/pkg/push"
@@ -15,160 +15,219 @@ import (
 
 type LogGenerator func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter)
 
-var generators = map[model.LabelValue]map[model.LabelValue]LogGenerator{
-	"gateway": {
-		"apache": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewApacheCommonLog(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+// services are the services emitted by the generator, with their generator
+// and what -list and -describe print about them. Services added in their own
+// files call register from init().
+var services = []ServiceInfo{
+	{
+		Namespace: "gateway", Name: "apache", Format: "apache_common", Description: "Apache common log access lines", Fields: apacheFields, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewApacheCommonLog(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
-		"httpd": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewApacheCombinedLog(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+	},
+	{
+		Namespace: "gateway", Name: "httpd", Format: "apache_combined", Description: "Apache combined log access lines", Fields: append(apacheFields, "referrer", "agent"), Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewApacheCombinedLog(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
-		"nginx": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewCommonLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+	},
+	{
+		Namespace: "gateway", Name: "nginx", Format: "common_log", Description: "Common log format access lines without structured metadata", Fields: apacheFields, noMetadata: true,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewCommonLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
-		"nginx-json": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+	},
+	{
+		Namespace: "gateway", Name: "nginx-json", Format: "json", Description: "JSON access lines with nested objects", Fields: jsonLogFields, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
-		"nginx-json-mixed": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					if level == log.ERROR {
-						log := flog.NewCommonLogFormat(t, log.RandURI(), statusFromLevel(level))
-						// Add a stacktrace to the logfmt log, and include a field that will conflict with stream selectors
-						logger.LogWithMetadata(level, t, fmt.Sprintf("%s %s", log, `method=GET namespace=whoopsie caller=flush.go:253 stacktrace="Exception in thread \"main\" java.lang.NullPointerException\n        at com.example.myproject.Book.getTitle(Book.java:16)\n        at com.example.myproject.Author.getBookTitles(Author.java:25)\n        at com.example.myproject.Bootstrap.main(Bootstrap.java:14)"`), metadata)
-					}
-					logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
+	},
+	{
+		Namespace: "gateway", Name: "nginx-json-mixed", Format: "json,logfmt", Description: "JSON access lines; errors add a logfmt line with a stacktrace and a namespace field colliding with the stream label", Fields: append(jsonLogFields, "caller", "namespace", "stacktrace"), Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				if level == log.ERROR {
+					log := flog.NewCommonLogFormat(t, log.RandURI(), statusFromLevel(level))
+					// Add a stacktrace to the logfmt log, and include a field that will conflict with stream selectors
+					logger.LogWithMetadata(level, t, fmt.Sprintf("%s %s", log, `method=GET namespace=whoopsie caller=flush.go:253 stacktrace="Exception in thread \"main\" java.lang.NullPointerException\n        at com.example.myproject.Book.getTitle(Book.java:16)\n        at com.example.myproject.Author.getBookTitles(Author.java:25)\n        at com.example.myproject.Bootstrap.main(Bootstrap.java:14)"`), metadata)
 				}
+				logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
 	},
-
-	"mimir-dev": {
-		"mimir-ingester":    mimirPod,
-		"mimir-distributor": mimirPod,
-		"mimir-querier":     mimirPod,
-		"mimir-ruler":       mimirPod,
+	{
+		Namespace: "mimir-dev", Name: "mimir-ingester", Format: "logfmt", Description: "Mimir gRPC request logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: mimirPod,
 	},
-	"mimir-prod": {
-		"mimir-ingester": mimirPod,
+	{
+		Namespace: "mimir-dev", Name: "mimir-distributor", Format: "logfmt", Description: "Mimir gRPC request logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: mimirPod,
 	},
-	"tempo-prod": {
-		"tempo-ingester":    noisyTempo,
-		"tempo-distributor": noisyTempo,
+	{
+		Namespace: "mimir-dev", Name: "mimir-querier", Format: "logfmt", Description: "Mimir gRPC request logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: mimirPod,
 	},
-	"tempo-dev": {
-		"tempo-ingester":    noisyTempo,
-		"tempo-distributor": noisyTempo,
+	{
+		Namespace: "mimir-dev", Name: "mimir-ruler", Format: "logfmt", Description: "Mimir gRPC request logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: mimirPod,
 	},
-	"loki-otel": {
-		"loki-ingester-otel":      lokiOtelPod("loki-ingester-otel"),
-		"loki-querier-otel":       lokiOtelPod("loki-querier-otel"),
-		"loki-queryfrontend-otel": lokiOtelPod("loki-queryfrontend-otel"),
-		"loki-distributor-otel":   lokiOtelPod("loki-distributor-otel"),
+	{
+		Namespace: "mimir-prod", Name: "mimir-ingester", Format: "logfmt", Description: "Mimir gRPC request logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: mimirPod,
 	},
-	"grafanacon": {
-		"grafanacon-json-otel": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+	{
+		Namespace: "tempo-prod", Name: "tempo-ingester", Format: "logfmt", Description: "Eight recurring Tempo log patterns, pods named tempo-ingester-hc-<n> for E2E tests", Fields: tempoFields, Metadata: defaultMetadata,
+		generator: noisyTempo,
+	},
+	{
+		Namespace: "tempo-prod", Name: "tempo-distributor", Format: "logfmt", Description: "Eight recurring Tempo log patterns", Fields: tempoFields, Metadata: defaultMetadata,
+		generator: noisyTempo,
+	},
+	{
+		Namespace: "tempo-dev", Name: "tempo-ingester", Format: "logfmt", Description: "Eight recurring Tempo log patterns, pods named tempo-ingester-hc-<n> for E2E tests", Fields: tempoFields, Metadata: defaultMetadata,
+		generator: noisyTempo,
+	},
+	{
+		Namespace: "tempo-dev", Name: "tempo-distributor", Format: "logfmt", Description: "Eight recurring Tempo log patterns", Fields: tempoFields, Metadata: defaultMetadata,
+		generator: noisyTempo,
+	},
+	{
+		Namespace: "loki-otel", Name: "loki-ingester-otel", Format: "logfmt", Description: "Loki ingester gRPC logs, one stream per level", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: lokiOtelPod("loki-ingester-otel"),
+	},
+	{
+		Namespace: "loki-otel", Name: "loki-querier-otel", Format: "logfmt", Description: "Loki querier logs with pattern placeholders", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: lokiOtelPod("loki-querier-otel"),
+	},
+	{
+		Namespace: "loki-otel", Name: "loki-queryfrontend-otel", Format: "logfmt", Description: "Loki query-frontend logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: lokiOtelPod("loki-queryfrontend-otel"),
+	},
+	{
+		Namespace: "loki-otel", Name: "loki-distributor-otel", Format: "logfmt", Description: "Loki distributor push and tee logs", Fields: grpcLogFields, Metadata: defaultMetadata,
+		generator: lokiOtelPod("loki-distributor-otel"),
+	},
+	{
+		Namespace: "grafanacon", Name: "grafanacon-json-otel", Format: "json", Description: "JSON access lines shipped over OTLP", Fields: jsonLogFields, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
-		"grafanacon-otel": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
-					logger.Sleep()
-				}
+	},
+	{
+		Namespace: "grafanacon", Name: "grafanacon-otel", Format: "json", Description: "JSON access lines shipped over OTLP", Fields: jsonLogFields, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, flog.NewJSONLogFormat(t, log.RandURI(), statusFromLevel(level)), metadata)
 			})
 		},
 	},
-	"e-commerce": {
-		"shopping-cart-otel": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-
-					var logLine string
-					if level == log.WARN {
-						logLine = fmt.Sprintf("order %d is not valid", gofakeit.Number(1, 10000))
-					} else if level == log.ERROR {
-						logLine = fmt.Sprintf("error processing order %d", gofakeit.Number(1, 10000))
-					} else {
-						logLine = flog.NewShoppingCart(t)
-					}
-					logger.LogWithMetadata(level, t, logLine, metadata)
-					logger.Sleep()
-				}
+	{
+		Namespace: "system-logs", Name: "apache-error", Format: "apache_error", Description: "Apache error log lines with a severity matching the level", Fields: []string{"timestamp", "module", "severity", "pid", "tid", "client", "message"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewApacheErrorLogWithSeverity(t, apacheSeverity(level)), metadata)
 			})
 		},
-		"shopping-cart-structured-otel": func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					level := log.RandLevel()
-					t := logger.Now()
-
-					var logLine string
-					newLabels := metadata
-					if level == log.WARN {
-						logLine = fmt.Sprintf("order %d is not valid", gofakeit.Number(1, 10000))
-					} else if level == log.ERROR {
-						logLine = fmt.Sprintf("error processing order %d", gofakeit.Number(1, 10000))
-					} else {
-						var labels push.LabelsAdapter
-						logLine, labels = flog.NewShoppingCartWithMetadata(t)
-						newLabels = make(push.LabelsAdapter, len(labels)+len(metadata))
-						for _, label := range labels {
-							newLabels = append(newLabels, label)
-						}
-						for _, v := range metadata {
-							newLabels = append(newLabels, v)
-						}
-					}
-
-					logger.LogWithMetadata(level, t, logLine, newLabels)
-					logger.Sleep()
-				}
+	},
+	{
+		Namespace: "system-logs", Name: "syslog-rfc3164", Format: "rfc3164", Description: "BSD syslog lines pushed straight to Loki, the priority's severity matching the level", Fields: []string{"priority", "timestamp", "hostname", "application", "pid", "message"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewRFC3164LogWithPriority(t, syslogPriority(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "system-logs", Name: "syslog-rfc5424", Format: "rfc5424", Description: "RFC5424 syslog lines with random structured data pushed straight to Loki, the priority's severity matching the level", Fields: []string{"priority", "version", "timestamp", "hostname", "application", "pid", "msgid", "structured_data", "message"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewRFC5424LogWithPriority(t, syslogPriority(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "system-logs", Name: "iis", Format: "iis_w3c", Description: "IIS W3C extended log access lines", Fields: []string{"date", "time", "s-ip", "cs-method", "cs-uri-stem", "cs-uri-query", "s-port", "cs-username", "c-ip", "cs(User-Agent)", "cs(Referer)", "sc-status", "sc-substatus", "sc-win32-status", "time-taken"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewIISW3CLog(t, log.RandURI(), statusFromLevel(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "system-logs", Name: "logfmt-app", Format: "logfmt", Description: "logfmt request lines with integer, float, duration, boolean and quoted string fields", Fields: []string{"ts", "level", "msg", "method", "path", "status", "duration", "bytes", "ratio", "cached", "user"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewLogfmtLog(t, log.RandURI(), statusFromLevel(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "system-logs", Name: "cef-firewall", Format: "cef", Description: "Firewall events in ArcSight Common Event Format with escaped header and extension values", Fields: []string{"vendor", "product", "version", "signature", "name", "severity", "rt", "src", "spt", "dst", "dpt", "proto", "act", "suser", "request", "msg"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewCEFLog(t, securitySeverity(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "system-logs", Name: "leef-firewall", Format: "leef", Description: "Firewall events in QRadar LEEF 1.0 with tab separated attributes", Fields: []string{"vendor", "product", "version", "eventID", "devTime", "devTimeFormat", "cat", "sev", "src", "srcPort", "dst", "dstPort", "proto", "usrName", "action", "reason"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewLEEFLog(t, securitySeverity(level)), metadata)
 			})
 		},
 	},
+	{
+		Namespace: "system-logs", Name: "gelf-app", Format: "gelf", Description: "GELF JSON messages with a syslog severity level and typed additional fields", Fields: []string{"version", "host", "short_message", "full_message", "timestamp", "level", "_user_id", "_request_uri", "_duration_ms", "_cached", "_facility"}, Metadata: defaultMetadata,
+		generator: func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+			gen := streamFlog(logger, metadata)
+			logger.Schedule(func(t time.Time) {
+				level := log.RandLevel()
+				logger.LogWithMetadata(level, t, gen.NewGELFLog(t, syslogSeverity(level)), metadata)
+			})
+		},
+	},
+	{
+		Namespace: "e-commerce", Name: "shopping-cart-otel", Format: "text", Description: "Order placement messages with fields inline", Fields: shoppingFields, Metadata: defaultMetadata,
+		generator: shoppingCart("shopping-cart-otel", flog.NewShoppingCartOrder),
+	},
+	{
+		Namespace: "e-commerce", Name: "shopping-cart-structured-otel", Format: "text", Description: "Order placement messages with fields duplicated into structured metadata", Fields: shoppingFields, Metadata: append(append([]string{}, shoppingFields...), defaultMetadata...),
+		generator: shoppingCart("shopping-cart-structured-otel", flog.NewCorrelatedShoppingCartOrder),
+	},
+	{Namespace: "mimir", Name: "mimir-ingester", Format: "logfmt", Description: "Single failing Mimir ingester pod in the first cluster, half of its lines are errors", Fields: grpcLogFields, Metadata: defaultMetadata, Standalone: true, generator: failingMimirPod, stream: failingMimirPodStream},
 }
 
 func lokiOtelPod(svc string) LogGenerator {
@@ -191,14 +250,15 @@ func lokiOtelPod(svc string) LogGenerator {
 	}
 	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
 		serviceLogs := logs[svc]
-		for k, v := range serviceLogs {
-			level, line := k, v
-			log.Go(func() {
-				for ctx.Err() == nil && !logger.Done() {
-					t := logger.Now()
-					logger.LogWithMetadata(level, t, line, log.RandStructuredMetadata("loki-ingester", 0))
-					logger.Sleep()
-				}
+		levels := make([]model.LabelValue, 0, len(serviceLogs))
+		for level := range serviceLogs {
+			levels = append(levels, level)
+		}
+		slices.Sort(levels)
+		for _, level := range levels {
+			line := serviceLogs[level]
+			logger.Schedule(func(t time.Time) {
+				logger.LogWithMetadata(level, t, line, log.RandStreamMetadata(metadata))
 			})
 		}
 	}
@@ -213,101 +273,71 @@ var noisyTempo = func(ctx context.Context, logger *log.AppLogger, metadata push.
 	const fmt6 = `level=error ts=%s caller=memcached.go:153 msg="Failed to get keys from memcached" err="memcache: connect timeout to %s:11211"`
 	const fmt7 = `level=info ts=%s caller=registry.go:232 tenant=%s msg="collecting metrics" active_series=%d`
 	const fmt8 = `level=info ts=%s caller=main.go:107 msg="Starting Grafana Enterprise Traces" version="version=weekly-r138-f1920489, branch=weekly-r138, revision=f1920489"`
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.DEBUG, t, fmt.Sprintf(fmt1, t.Format(time.RFC3339Nano), rand.Intn(100), rand.Intn(100), log.RandSeq(5), log.RandSeq(5)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.DEBUG, t, fmt.Sprintf(fmt1, t.Format(time.RFC3339Nano), rand.Intn(100), rand.Intn(100), log.RandSeq(5), log.RandSeq(5)), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.WARN, t, fmt.Sprintf(fmt2, t.Format(time.RFC3339Nano), log.RandOrgID()), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.WARN, t, fmt.Sprintf(fmt2, t.Format(time.RFC3339Nano), log.RandOrgID()), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt3, t.Format(time.RFC3339Nano), rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt3, t.Format(time.RFC3339Nano), rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt4, t.Format(time.RFC3339Nano), rand.Intn(1000)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt4, t.Format(time.RFC3339Nano), rand.Intn(1000)), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt5, t.Format(time.RFC3339Nano), log.RandOrgID(), log.RandSeq(5)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt5, t.Format(time.RFC3339Nano), log.RandOrgID(), log.RandSeq(5)), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.ERROR, t, fmt.Sprintf(fmt6, t.Format(time.RFC3339Nano), flog.FakeIP()), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.ERROR, t, fmt.Sprintf(fmt6, t.Format(time.RFC3339Nano), flog.FakeIP()), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt7, t.Format(time.RFC3339Nano), log.RandOrgID(), rand.Intn(1000)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt7, t.Format(time.RFC3339Nano), log.RandOrgID(), rand.Intn(1000)), metadata)
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt8, t.Format(time.RFC3339Nano)), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, fmt.Sprintf(fmt8, t.Format(time.RFC3339Nano)), metadata)
 	})
 }
 
 var mimirPod = func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
-	log.Go(func() {
-		for ctx.Err() == nil && !logger.Done() {
-			t := logger.Now()
-			logger.LogWithMetadata(log.INFO, t, mimirGRPCLog(t, "", "/cortex.Ingester/Push"), metadata)
-			logger.Sleep()
-		}
+	logger.Schedule(func(t time.Time) {
+		logger.LogWithMetadata(log.INFO, t, mimirGRPCLog(t, "", "/cortex.Ingester/Push"), metadata)
 	})
 }
 
-func startFailingMimirPod(ctx context.Context, logger log.Logger) {
-	appLogger := log.NewAppLogger(model.LabelSet{
-		"cluster":      model.LabelValue(log.Clusters[0]),
+// failingMimirPodStream returns the stream labels and metadata of the single
+// failing Mimir pod. Unlike the other services it is not spread
+// across clusters.
+func failingMimirPodStream() (model.LabelSet, push.LabelsAdapter) {
+	cluster := log.Clusters[0]
+	labels := model.LabelSet{
+		"cluster":      model.LabelValue(cluster),
 		"namespace":    model.LabelValue("mimir"),
 		"service_name": "mimir-ingester",
-	}, logger)
+	}
+	pod := "mimir-ingester-" + log.ReplicaSetHash(cluster, "mimir", "mimir-ingester", 0) + "-0"
+	return labels, push.LabelsAdapter{{Name: "pod", Value: pod}, {Name: "node", Value: log.PodNode(cluster, pod)}}
+}
+
+func startFailingMimirPod(ctx context.Context, logger log.Logger) {
+	labels, metadata := failingMimirPodStream()
+	appLogger := log.NewAppLogger(labels, logger)
 	if log.UseFullDataForService("mimir-ingester") {
 		if log.IsCIData() {
-			appLogger.SetSleep(log.LogSleepOriginal)
+			appLogger.SetInterval(log.LogIntervalOriginal)
 		} else {
-			appLogger.SetSleep(log.LogSleepFast)
+			appLogger.SetInterval(log.LogIntervalFast)
 		}
 	}
+	failingMimirPod(ctx, appLogger, metadata)
+}
 
-	log.Go(func() {
-		for ctx.Err() == nil && !appLogger.Done() {
-			t := appLogger.Now()
-			appLogger.LogWithMetadata(log.ERROR, t, mimirGRPCLog(t, "connection refused to object store", "/cortex.Ingester/Push"), log.RandStructuredMetadata("mimir-ingester", 0))
-			appLogger.Sleep()
-		}
+var failingMimirPod = func(ctx context.Context, appLogger *log.AppLogger, metadata push.LabelsAdapter) {
+	appLogger.Schedule(func(t time.Time) {
+		appLogger.LogWithMetadata(log.ERROR, t, mimirGRPCLog(t, "connection refused to object store", "/cortex.Ingester/Push"), log.RandStreamMetadata(metadata))
 	})
-	log.Go(func() {
-		for ctx.Err() == nil && !appLogger.Done() {
-			t := appLogger.Now()
-			appLogger.LogWithMetadata(log.INFO, t, mimirGRPCLog(t, "", "/cortex.Ingester/Push"), log.RandStructuredMetadata("mimir-ingester", 0))
-			appLogger.Sleep()
-		}
+	appLogger.Schedule(func(t time.Time) {
+		appLogger.LogWithMetadata(log.INFO, t, mimirGRPCLog(t, "", "/cortex.Ingester/Push"), log.RandStreamMetadata(metadata))
 	})
 }
 
@@ -377,6 +407,55 @@ func lokiGRPCLog(t time.Time, err, path string) string {
 	return out
 }
 
+// streamFlog returns a flog.Generator of its own for the stream of logger
+// and metadata, so its lines only depend on the seed in static mode.
+func streamFlog(logger *log.AppLogger, metadata push.LabelsAdapter) *flog.Generator {
+	return flog.NewGenerator(rand.New(rand.NewSource(log.StreamSeed(logger.Labels(), metadata))))
+}
+
+// apacheSeverity returns an Apache error log severity of level.
+func apacheSeverity(level model.LabelValue) string {
+	severities := map[model.LabelValue][]string{
+		log.DEBUG: {"debug", "trace1", "trace4"},
+		log.INFO:  {"info", "notice"},
+		log.WARN:  {"warn"},
+		log.ERROR: {"error", "crit", "alert"},
+	}[level]
+	if len(severities) == 0 {
+		return "info"
+	}
+	return severities[rand.Intn(len(severities))]
+}
+
+// syslogSeverity returns the syslog severity of level, also used as GELF level.
+func syslogSeverity(level model.LabelValue) int {
+	severity, ok := map[model.LabelValue]int{log.DEBUG: 7, log.INFO: 6, log.WARN: 4, log.ERROR: 3}[level]
+	if !ok {
+		return 6
+	}
+	return severity
+}
+
+// syslogPriority returns a syslog priority with a random facility and the
+// severity of level.
+func syslogPriority(level model.LabelValue) int {
+	return rand.Intn(24)*8 + syslogSeverity(level)
+}
+
+// securitySeverity returns a CEF or LEEF severity, from 1 to 10, of level.
+func securitySeverity(level model.LabelValue) int {
+	switch level {
+	case log.DEBUG:
+		return 1 + rand.Intn(2)
+	case log.WARN:
+		return 5 + rand.Intn(3)
+	case log.ERROR:
+		return 8 + rand.Intn(3)
+	default:
+		return 3 + rand.Intn(2)
+	}
+}
+
 func statusFromLevel(level model.LabelValue) int {
 	switch level {
 	case log.INFO:
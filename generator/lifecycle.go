@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// Services whose pods come and go: rollouts replace pod names, restarts bump
+// the restarts metadata, and crash-looping pods stop logging while backing
+// off. Pods appear in structured metadata only for part of the time range.
+var lifecycleServices = []struct {
+	name        model.LabelValue
+	description string
+	cfg         log.LifecycleConfig
+}{
+	{
+		name:        "checkout-api",
+		description: "Deployment rolled out every 15 minutes; old pods drain and stop while new pods start",
+		cfg:         log.LifecycleConfig{RolloutInterval: 15 * time.Minute, RolloutDuration: 3 * time.Minute},
+	},
+	{
+		name:        "inventory-worker",
+		description: "Worker whose containers restart roughly every 20 minutes",
+		cfg:         log.LifecycleConfig{RestartInterval: 20 * time.Minute},
+	},
+	{
+		name:        "payment-gateway",
+		description: "First replica crash loops with exponential back-off; deployment rolled out hourly",
+		cfg:         log.LifecycleConfig{RolloutInterval: time.Hour, RolloutDuration: 5 * time.Minute, CrashLoopReplicas: 1, CrashAfter: 45 * time.Second},
+	},
+}
+
+var lifecycleFields = []string{"level", "ts", "caller", "msg", "method", "path", "status", "duration", "version", "node", "restarts", "err"}
+
+func init() {
+	for _, svc := range lifecycleServices {
+		log.DefaultTopology.SetLifecycle("checkout", svc.name, svc.cfg)
+		register(ServiceInfo{
+			Namespace:   "checkout",
+			Name:        svc.name,
+			Description: svc.description,
+			Format:      "logfmt",
+			Fields:      lifecycleFields,
+			Metadata:    append(append([]string{}, defaultMetadata...), "restarts"),
+		}, lifecycleGenerator("checkout", svc.name, svc.cfg))
+	}
+}
+
+// lifecycleGenerator returns a LogGenerator that follows the pod it is
+// started for through cfg, logging startup, shutdown, restart and crash
+// events in between regular request lines.
+func lifecycleGenerator(namespace, svc model.LabelValue, cfg log.LifecycleConfig) LogGenerator {
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		pod := log.DefaultTopology.Pod(namespace, svc, log.MetadataValue(metadata, "pod"))
+		if pod == nil {
+			return
+		}
+		lifecycle := log.NewPodLifecycle(pod, cfg)
+		logger.Schedule(func(t time.Time) {
+			state, stopped, events := lifecycle.Advance(t)
+			for _, event := range events {
+				switch event {
+				case log.PodStopped:
+					logger.LogWithMetadata(log.INFO, t, lifecycleLine(log.INFO, t, "main.go:97", `msg="received SIGTERM, draining connections"`), stopped.Metadata())
+					logger.LogWithMetadata(log.INFO, t, lifecycleLine(log.INFO, t, "main.go:104", `msg="shutdown complete"`), stopped.Metadata())
+				case log.PodStarted, log.PodRestarted:
+					if event == log.PodRestarted {
+						logger.LogWithMetadata(log.WARN, t, lifecycleLine(log.WARN, t, "main.go:41", fmt.Sprintf(`msg="container restarted" restarts=%d`, state.Restarts)), state.Metadata())
+					}
+					logger.LogWithMetadata(log.INFO, t, lifecycleLine(log.INFO, t, "main.go:52", fmt.Sprintf(`msg="starting %s" version=%s node=%s`, svc, state.ReplicaSet, state.Node)), state.Metadata())
+					logger.LogWithMetadata(log.INFO, t, lifecycleLine(log.INFO, t, "server.go:64", `msg="server listening" addr=:8080`), state.Metadata())
+				case log.PodCrashed:
+					logger.LogWithMetadata(log.ERROR, t, lifecycleLine(log.ERROR, t, "main.go:71", fmt.Sprintf(`msg="unrecoverable error, exiting" err="%s"`, log.RandError())), state.Metadata())
+					logger.LogWithMetadata(log.ERROR, t, "panic: runtime error: invalid memory address or nil pointer dereference", state.Metadata())
+				}
+			}
+			if !state.Running {
+				return
+			}
+			level := log.RandLevel()
+			logger.LogWithMetadata(level, t, lifecycleLine(level, t, "handler.go:112", fmt.Sprintf(`msg="request served" method=GET path=%s status=%d duration=%s`, log.RandURI(), statusFromLevel(level), log.RandDuration())), state.Metadata())
+		})
+	}
+}
+
+func lifecycleLine(level model.LabelValue, t time.Time, caller, rest string) string {
+	return fmt.Sprintf("level=%s ts=%s caller=%s %s", level, t.Format(time.RFC3339Nano), caller, rest)
+}
@@ -10,18 +10,21 @@ import (
 )
 
 type AppLogger struct {
-	labels  model.LabelSet
-	levels  map[model.LabelValue]model.LabelSet
-	logger  Logger
-	sleepFn func()
+	labels     model.LabelSet
+	levels     map[model.LabelValue]model.LabelSet
+	logger     Logger
+	intervalFn func() time.Duration
+	scheduler  *Scheduler
 
-	// Static-mode state. When static is non-nil, Now() returns timestamps
-	// derived from the virtual clock, Sleep() advances the iteration counter
-	// (with a small real-time throttle), and Done() reports when this logger
-	// has emitted enough logs to cover the configured window.
-	static      *StaticConfig
-	staticIdx   atomic.Int64
-	staticIters int64
+	// Static-mode state. When static is non-nil, the scheduler derives
+	// timestamps from the virtual clock and stops once this logger's
+	// emitters have produced staticIters lines between them. staticStride
+	// is the number of emitters sharing them, fixed when the first of them
+	// runs.
+	static         *StaticConfig
+	staticEmitters atomic.Int64
+	staticStride   atomic.Int64
+	staticIters    int64
 }
 
 func NewAppLogger(labels model.LabelSet, logger Logger) *AppLogger {
@@ -32,10 +35,11 @@ func NewAppLogger(labels model.LabelSet, logger Logger) *AppLogger {
 		ERROR: labels.Merge(model.LabelSet{"level": ERROR}),
 	}
 	app := &AppLogger{
-		labels:  labels,
-		levels:  levels,
-		logger:  logger,
-		sleepFn: LogSleep,
+		labels:     labels,
+		levels:     levels,
+		logger:     logger,
+		intervalFn: LogInterval,
+		scheduler:  defaultScheduler,
 	}
 	if cfg := CurrentStatic(); cfg != nil {
 		app.static = cfg
@@ -44,48 +48,37 @@ func NewAppLogger(labels model.LabelSet, logger Logger) *AppLogger {
 	return app
 }
 
-// SetSleep sets the sleep function used by Sleep(). When nil, uses LogSleep.
-// In static mode, the configured sleepFn is ignored.
-func (app *AppLogger) SetSleep(fn func()) {
+// Labels returns the labels of the streams of app, without the level.
+func (app *AppLogger) Labels() model.LabelSet {
+	return app.labels
+}
+
+// SetInterval sets the function returning the live-mode pause between two
+// lines of the same emitter. When nil, uses LogInterval. In static mode the
+// configured interval is ignored.
+func (app *AppLogger) SetInterval(fn func() time.Duration) {
 	if fn != nil {
-		app.sleepFn = fn
+		app.intervalFn = fn
 	} else {
-		app.sleepFn = LogSleep
+		app.intervalFn = LogInterval
 	}
 }
 
-// Sleep advances the loop. In live mode it calls the configured sleep
-// function; in static mode it bumps the virtual clock and pauses briefly to
-// avoid overwhelming the ingester.
-func (app *AppLogger) Sleep() {
-	if app.static != nil {
-		app.staticIdx.Add(1)
-		if app.static.Throttle > 0 {
-			time.Sleep(app.static.Throttle)
-		}
-		return
-	}
-	app.sleepFn()
+// SetScheduler sets the scheduler that runs the emitters registered with
+// Schedule. Defaults to the one run by RunScheduler.
+func (app *AppLogger) SetScheduler(s *Scheduler) {
+	app.scheduler = s
 }
 
-// Now returns the timestamp this logger should use for its next log line.
-// In live mode this is wall time; in static mode it is start + idx*step.
-func (app *AppLogger) Now() time.Time {
-	if app.static == nil {
-		return time.Now()
-	}
-	idx := app.staticIdx.Load()
-	return app.static.Start.Add(time.Duration(idx) * app.static.Step)
+// Schedule registers fn as a recurring emitter of this logger. The scheduler
+// calls fn with the timestamp of the line to emit: wall time in live mode,
+// and the virtual clock in static mode.
+func (app *AppLogger) Schedule(fn func(t time.Time)) {
+	app.scheduler.Add(app, fn)
 }
 
-// Done reports whether this AppLogger has produced enough logs in static
-// mode. Always false in live mode so existing context-driven loops keep
-// running.
-func (app *AppLogger) Done() bool {
-	if app.static == nil {
-		return false
-	}
-	return app.staticIdx.Load() >= app.staticIters
+func (app *AppLogger) interval() time.Duration {
+	return app.intervalFn()
 }
 
 func (app *AppLogger) Log(level model.LabelValue, t time.Time, message string) {
@@ -109,3 +102,19 @@ func (app *AppLogger) LogWithMetadata(level model.LabelValue, t time.Time, messa
 		log.Printf("Error logging message: %s", err)
 	}
 }
+
+// LogWithLabels logs message like LogWithMetadata, on the stream of level
+// with labels added, e.g. fields placed as stream labels.
+func (app *AppLogger) LogWithLabels(level model.LabelValue, t time.Time, message string, labels model.LabelSet, metadata push.LabelsAdapter) {
+	stream, ok := app.levels[level]
+	if !ok {
+		stream = app.labels
+	}
+	if len(labels) > 0 {
+		stream = stream.Merge(labels)
+	}
+	err := app.logger.HandleWithMetadata(stream, t, message, metadata)
+	if err != nil {
+		log.Printf("Error logging message: %s", err)
+	}
+}
@@ -0,0 +1,226 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Distribution is a probability distribution numeric fields are drawn from.
+// Its quantiles are known, so queries such as quantile_over_time over the
+// generated lines can be checked against them.
+type Distribution interface {
+	// Sample draws a value for a line at t.
+	Sample(t time.Time) float64
+	// CDF returns the probability of a sample being at most x, over a whole
+	// number of periods for distributions that change over time.
+	CDF(x float64) float64
+	String() string
+}
+
+// Normal is the normal distribution.
+type Normal struct{ Mean, StdDev float64 }
+
+func (d Normal) Sample(time.Time) float64 { return d.Mean + rand.NormFloat64()*d.StdDev }
+func (d Normal) CDF(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-d.Mean)/(d.StdDev*math.Sqrt2))
+}
+func (d Normal) String() string { return fmt.Sprintf("normal(mean=%g, stddev=%g)", d.Mean, d.StdDev) }
+
+// LogNormal is the distribution of exp(X) where X is Normal(Mu, Sigma).
+type LogNormal struct{ Mu, Sigma float64 }
+
+func (d LogNormal) Sample(time.Time) float64 { return math.Exp(d.Mu + rand.NormFloat64()*d.Sigma) }
+func (d LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal{d.Mu, d.Sigma}.CDF(math.Log(x))
+}
+func (d LogNormal) String() string { return fmt.Sprintf("lognormal(mu=%g, sigma=%g)", d.Mu, d.Sigma) }
+
+// Exponential is the exponential distribution with the given mean.
+type Exponential struct{ Mean float64 }
+
+func (d Exponential) Sample(time.Time) float64 { return rand.ExpFloat64() * d.Mean }
+func (d Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return 1 - math.Exp(-x/d.Mean)
+}
+func (d Exponential) String() string { return fmt.Sprintf("exponential(mean=%g)", d.Mean) }
+
+// Pareto is the Pareto distribution with scale Xm and shape Alpha: a long
+// tail where a few values are orders of magnitude above the median.
+type Pareto struct{ Xm, Alpha float64 }
+
+func (d Pareto) Sample(time.Time) float64 {
+	return d.Xm / math.Pow(1-rand.Float64(), 1/d.Alpha)
+}
+func (d Pareto) CDF(x float64) float64 {
+	if x < d.Xm {
+		return 0
+	}
+	return 1 - math.Pow(d.Xm/x, d.Alpha)
+}
+func (d Pareto) String() string { return fmt.Sprintf("pareto(xm=%g, alpha=%g)", d.Xm, d.Alpha) }
+
+// Bimodal draws from A with probability P and from B otherwise, e.g. cache
+// hits and misses.
+type Bimodal struct {
+	A, B Distribution
+	P    float64
+}
+
+func (d Bimodal) Sample(t time.Time) float64 {
+	if rand.Float64() < d.P {
+		return d.A.Sample(t)
+	}
+	return d.B.Sample(t)
+}
+func (d Bimodal) CDF(x float64) float64 { return d.P*d.A.CDF(x) + (1-d.P)*d.B.CDF(x) }
+func (d Bimodal) String() string {
+	return fmt.Sprintf("bimodal(%.3g×%s, %.3g×%s)", d.P, d.A, 1-d.P, d.B)
+}
+
+// Steps switches between Phases every Period, e.g. a latency regression
+// that comes and goes. Phases are aligned on the Unix epoch, so every
+// instance of the generator is in the same phase at the same time.
+type Steps struct {
+	Phases []Distribution
+	Period time.Duration
+}
+
+// Phase returns the distribution in effect at t.
+func (d Steps) Phase(t time.Time) Distribution {
+	return d.Phases[(t.UnixNano()/int64(d.Period))%int64(len(d.Phases))]
+}
+
+func (d Steps) Sample(t time.Time) float64 { return d.Phase(t).Sample(t) }
+func (d Steps) CDF(x float64) float64 {
+	var p float64
+	for _, phase := range d.Phases {
+		p += phase.CDF(x)
+	}
+	return p / float64(len(d.Phases))
+}
+func (d Steps) String() string {
+	phases := make([]string, len(d.Phases))
+	for i, p := range d.Phases {
+		phases[i] = p.String()
+	}
+	return fmt.Sprintf("steps(every %s: %s)", d.Period, strings.Join(phases, " → "))
+}
+
+// Quantile returns the value below which a fraction q of the samples of d
+// fall, found by bisection on its CDF.
+func Quantile(d Distribution, q float64) float64 {
+	lo, hi := -1.0, 1.0
+	for d.CDF(lo) > q {
+		lo *= 2
+	}
+	for d.CDF(hi) < q {
+		hi *= 2
+	}
+	for i := 0; i < 200 && hi-lo > 1e-9*math.Max(1, math.Abs(hi)); i++ {
+		mid := (lo + hi) / 2
+		if d.CDF(mid) < q {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Units numeric fields are written in, the way applications usually log them.
+const (
+	// UnitMilliseconds writes "123ms".
+	UnitMilliseconds = "ms"
+	// UnitDuration writes a Go duration of that many milliseconds, e.g. "1.5s".
+	UnitDuration = "duration"
+	// UnitBytes writes decimal SI sizes, e.g. "4.2MB".
+	UnitBytes = "bytes"
+	// UnitScientific writes "1.5e3".
+	UnitScientific = "scientific"
+)
+
+// FieldDistribution is a numeric field of a line drawn from Dist and
+// written in Unit (plain decimal when empty).
+type FieldDistribution struct {
+	Field string
+	Unit  string
+	Dist  Distribution
+}
+
+// Value draws the field's value for a line at t, formatted in its unit.
+func (f FieldDistribution) Value(t time.Time) string {
+	return FormatUnit(f.Dist.Sample(t), f.Unit)
+}
+
+// Quantiles returns the expected p50, p90, p95 and p99 of the field, formatted in its unit.
+func (f FieldDistribution) Quantiles() map[string]string {
+	out := map[string]string{}
+	for _, q := range []float64{0.5, 0.9, 0.95, 0.99} {
+		out[fmt.Sprintf("p%g", q*100)] = FormatUnit(Quantile(f.Dist, q), f.Unit)
+	}
+	return out
+}
+
+// MarshalJSON documents the field with its distribution and expected quantiles.
+func (f FieldDistribution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field        string            `json:"field"`
+		Unit         string            `json:"unit,omitempty"`
+		Distribution string            `json:"distribution"`
+		Quantiles    map[string]string `json:"quantiles"`
+	}{f.Field, f.Unit, f.Dist.String(), f.Quantiles()})
+}
+
+// FormatUnit writes v in unit.
+func FormatUnit(v float64, unit string) string {
+	switch unit {
+	case UnitMilliseconds:
+		return strconv.FormatFloat(math.Round(v), 'f', -1, 64) + "ms"
+	case UnitDuration:
+		return time.Duration(v * float64(time.Millisecond)).Round(time.Microsecond).String()
+	case UnitBytes:
+		return formatBytes(v)
+	case UnitScientific:
+		mantissa, exp := v, 0
+		for math.Abs(mantissa) >= 10 {
+			mantissa /= 10
+			exp++
+		}
+		for mantissa != 0 && math.Abs(mantissa) < 1 {
+			mantissa *= 10
+			exp--
+		}
+		mantissa = math.Round(mantissa*10) / 10
+		if math.Abs(mantissa) >= 10 {
+			mantissa /= 10
+			exp++
+		}
+		return strconv.FormatFloat(mantissa, 'f', -1, 64) + "e" + strconv.Itoa(exp)
+	default:
+		return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+	}
+}
+
+func formatBytes(v float64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for math.Abs(v) >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatFloat(math.Round(v), 'f', -1, 64) + units[i]
+	}
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64) + units[i]
+}
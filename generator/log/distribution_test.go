@@ -0,0 +1,56 @@
+package log
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributionQuantiles(t *testing.T) {
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	for _, d := range []Distribution{
+		Normal{Mean: 200, StdDev: 30},
+		LogNormal{Mu: 3, Sigma: 0.5},
+		Exponential{Mean: 2000},
+		Pareto{Xm: 10, Alpha: 1.5},
+		Bimodal{A: Normal{Mean: 10, StdDev: 1}, B: Normal{Mean: 100, StdDev: 10}, P: 0.7},
+		Steps{Period: time.Minute, Phases: []Distribution{Normal{Mean: 5, StdDev: 1}, Normal{Mean: 50, StdDev: 10}}},
+	} {
+		t.Run(d.String(), func(t *testing.T) {
+			samples := make([]float64, 20000)
+			for i := range samples {
+				// Spread samples evenly over two Steps periods.
+				samples[i] = d.Sample(start.Add(time.Duration(i) * 2 * time.Minute / time.Duration(len(samples))))
+			}
+			sort.Float64s(samples)
+			for _, q := range []float64{0.5, 0.9, 0.99} {
+				// The share of samples below the expected quantile is q.
+				below := sort.SearchFloat64s(samples, Quantile(d, q))
+				assert.InDelta(t, q, float64(below)/float64(len(samples)), 0.02, "p%g", q*100)
+			}
+		})
+	}
+	assert.InDelta(t, 200, Quantile(Normal{Mean: 200, StdDev: 30}, 0.5), 1e-6)
+	assert.InDelta(t, 2000*math.Ln2, Quantile(Exponential{Mean: 2000}, 0.5), 1e-6)
+}
+
+func TestFormatUnit(t *testing.T) {
+	assert.Equal(t, "123ms", FormatUnit(123.4, UnitMilliseconds))
+	assert.Equal(t, "1.5s", FormatUnit(1500, UnitDuration))
+	assert.Equal(t, "4.2MB", FormatUnit(4_200_000, UnitBytes))
+	assert.Equal(t, "512B", FormatUnit(512, UnitBytes))
+	assert.Equal(t, "1.5e3", FormatUnit(1500, UnitScientific))
+	assert.Equal(t, "1e1", FormatUnit(9.99, UnitScientific))
+	assert.Equal(t, "2.5e-2", FormatUnit(0.025, UnitScientific))
+	assert.Equal(t, "3.142", FormatUnit(math.Pi, ""))
+}
+
+func TestFieldDistributionQuantiles(t *testing.T) {
+	f := FieldDistribution{Field: "latency", Unit: UnitMilliseconds, Dist: Normal{Mean: 200, StdDev: 30}}
+	q := f.Quantiles()
+	assert.Equal(t, "200ms", q["p50"])
+	assert.Equal(t, "270ms", q["p99"])
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// LokiMaxLineSize is Loki's default limits_config.max_line_size.
+const LokiMaxLineSize = 256 * 1024
+
+// EdgeCase is a kind of adversarial line content, emitted with a relative
+// Weight among the other edge cases.
+type EdgeCase struct {
+	Name        string
+	Description string
+	Weight      int
+	Line        func() string
+}
+
+// EdgeCases are the kinds of adversarial content emitted by the edge case
+// service, with their default weights.
+var EdgeCases = []EdgeCase{
+	{Name: "long", Description: "line just under Loki's max line size", Weight: 1, Line: func() string { return wordsOfSize(LokiMaxLineSize - 1024) }},
+	{Name: "too_long", Description: "line beyond Loki's max line size, rejected or truncated by Loki", Weight: 1, Line: func() string { return wordsOfSize(LokiMaxLineSize + 1024) }},
+	{Name: "multiline", Description: "embedded newlines, as in a stack trace", Weight: 10, Line: multilineEdgeCase},
+	{Name: "tabs", Description: "tab separated columns and trailing whitespace", Weight: 5, Line: func() string {
+		return "2026-04-26T11:00:00Z\tinfo\tingester\tflushed chunk\t \t"
+	}},
+	{Name: "ansi", Description: "ANSI color and cursor escape codes", Weight: 10, Line: func() string {
+		return "\x1b[32mINFO\x1b[0m \x1b[1mserver started\x1b[0m on \x1b[4m:8080\x1b[0m \x1b[31;1mWARN\x1b[0m \x1b[2K\x1b[1Gprogress 42%"
+	}},
+	{Name: "invalid_utf8", Description: "byte sequences that are not valid UTF-8", Weight: 5, Line: func() string {
+		return "msg=\"decoded payload\" payload=\xff\xfe\xfd broken=\xc3\x28 overlong=\xe0\x80\xaf end=ok"
+	}},
+	{Name: "nul", Description: "NUL and other control bytes", Weight: 5, Line: func() string {
+		return "msg=\"binary frame\" data=\x00\x01\x02\x00\x7f bell=\a backspace=\b end=ok"
+	}},
+	{Name: "cjk", Description: "Chinese, Japanese and Korean text", Weight: 5, Line: func() string {
+		return `msg="用户登录成功" user=张伟 detail="ログインに失敗しました" note="요청이 완료되었습니다" path=/商品/一覧`
+	}},
+	{Name: "rtl", Description: "right-to-left Arabic and Hebrew mixed with left-to-right text", Weight: 5, Line: func() string {
+		return `msg="تم تسجيل الدخول بنجاح" user=محمد status=200 note="הבקשה נכשלה" id=42 mixed="abc עברית 123 عربي xyz"`
+	}},
+	{Name: "emoji", Description: "emoji including ZWJ sequences, skin tones and flags", Weight: 5, Line: func() string {
+		return `msg="deploy finished 🚀✅" by=👩🏽‍💻 team=👨‍👩‍👧‍👦 region=🇺🇸🇪🇺 mood=😀😅🔥 status=💯`
+	}},
+	{Name: "zero_width", Description: "zero-width spaces, joiners and byte order marks inside words", Weight: 5, Line: func() string {
+		return "\ufefflevel=err\u200bor msg=\"time\u200dout\" user=ad\u200cmin trace\u2060id=abc\u200b123"
+	}},
+	{Name: "long_token", Description: "a single token of tens of thousands of characters without whitespace", Weight: 3, Line: func() string {
+		return "token=" + RandSeq(32*1024)
+	}},
+}
+
+// ParseEdgeCaseWeights returns a copy of cases with weights overridden by
+// spec, a comma separated list of name=weight pairs (e.g. "too_long=0,ansi=20").
+func ParseEdgeCaseWeights(cases []EdgeCase, spec string) ([]EdgeCase, error) {
+	cases = append([]EdgeCase(nil), cases...)
+	err := parseWeights("edge cases", "edge case", spec, func(name string, weight int) bool {
+		found := false
+		for i := range cases {
+			if cases[i].Name == name {
+				cases[i].Weight = weight
+				found = true
+			}
+		}
+		return found
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// parseWeights calls set for every name=weight pair of spec. set reports
+// whether name is known. what and kind name the weighted things in errors.
+func parseWeights(what, kind, spec string, set func(name string, weight int) bool) error {
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("%s: expected name=weight, got %q", what, pair)
+		}
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("%s: invalid weight %q for %s", what, value, name)
+		}
+		if !set(name, weight) {
+			return fmt.Errorf("%s: unknown %s %q", what, kind, name)
+		}
+	}
+	return nil
+}
+
+// RandEdgeCase picks one of cases by weight. ok is false when all weights are zero.
+func RandEdgeCase(cases []EdgeCase) (c EdgeCase, ok bool) {
+	total := 0
+	for _, c := range cases {
+		total += c.Weight
+	}
+	if total == 0 {
+		return EdgeCase{}, false
+	}
+	r := rand.Intn(total)
+	for _, c := range cases {
+		if r -= c.Weight; r < 0 {
+			return c, true
+		}
+	}
+	return EdgeCase{}, false
+}
+
+func multilineEdgeCase() string {
+	return fmt.Sprintf("panic: %s\n\ngoroutine 1 [running]:\nmain.handler(0xc000010000)\n\t/app/main.go:42 +0x1d\nnet/http.HandlerFunc.ServeHTTP(...)\n\t/usr/local/go/src/net/http/server.go:2136\n\r\nexit status 2", RandError())
+}
+
+// wordsOfSize returns random lowercase words separated by spaces, exactly size bytes long.
+func wordsOfSize(size int) string {
+	var b strings.Builder
+	b.Grow(size)
+	for b.Len() < size {
+		b.WriteString(RandSeq(1 + rand.Intn(10)))
+		b.WriteByte(' ')
+	}
+	return b.String()[:size]
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdgeCaseLines(t *testing.T) {
+	lines := map[string]string{}
+	for _, c := range EdgeCases {
+		lines[c.Name] = c.Line()
+	}
+	assert.Len(t, lines["long"], LokiMaxLineSize-1024)
+	assert.Greater(t, len(lines["too_long"]), LokiMaxLineSize)
+	assert.Contains(t, lines["multiline"], "\n")
+	assert.Contains(t, lines["tabs"], "\t")
+	assert.NotContains(t, lines["tabs"], "\n", "newlines are the multiline kind")
+	assert.Contains(t, lines["ansi"], "\x1b[")
+	assert.False(t, utf8.ValidString(lines["invalid_utf8"]))
+	assert.Contains(t, lines["nul"], "\x00")
+	assert.Contains(t, lines["zero_width"], "\u200b")
+	assert.NotContains(t, lines["long_token"], " ")
+	assert.Greater(t, len(lines["long_token"]), 10000)
+}
+
+func TestParseEdgeCaseWeights(t *testing.T) {
+	cases, err := ParseEdgeCaseWeights(EdgeCases, "too_long=0, ansi=20")
+	require.NoError(t, err)
+	for _, c := range cases {
+		switch c.Name {
+		case "too_long":
+			assert.Zero(t, c.Weight)
+		case "ansi":
+			assert.Equal(t, 20, c.Weight)
+		}
+	}
+	assert.Equal(t, 1, EdgeCases[1].Weight, "defaults are not modified")
+
+	_, err = ParseEdgeCaseWeights(EdgeCases, "bogus=1")
+	assert.Error(t, err)
+	_, err = ParseEdgeCaseWeights(EdgeCases, "ansi")
+	assert.Error(t, err)
+	_, err = ParseEdgeCaseWeights(EdgeCases, "ansi=-1")
+	assert.Error(t, err)
+}
+
+func TestRandEdgeCaseHonoursWeights(t *testing.T) {
+	spec := make([]string, 0, len(EdgeCases))
+	for _, c := range EdgeCases {
+		if c.Name != "emoji" {
+			spec = append(spec, c.Name+"=0")
+		}
+	}
+	cases, err := ParseEdgeCaseWeights(EdgeCases, strings.Join(spec, ","))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		c, ok := RandEdgeCase(cases)
+		require.True(t, ok)
+		assert.Equal(t, "emoji", c.Name)
+	}
+
+	cases, err = ParseEdgeCaseWeights(EdgeCases, strings.Join(append(spec, "emoji=0"), ","))
+	require.NoError(t, err)
+	_, ok := RandEdgeCase(cases)
+	assert.False(t, ok)
+}
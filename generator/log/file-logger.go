@@ -0,0 +1,339 @@
+package log
+
+import (
+	"compress/gzip"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultFileLayout is the layout of FileLogger files unless configured
+// otherwise: one file per pod under a directory per namespace and service.
+const DefaultFileLayout = "<namespace>/<service_name>/<pod>.log"
+
+// DefaultMaxOpenFiles is the number of files a FileLogger keeps open unless
+// configured otherwise.
+const DefaultMaxOpenFiles = 256
+
+// FileLoggerConfig configures a FileLogger.
+type FileLoggerConfig struct {
+	// Dir is the directory the files are written under.
+	Dir string
+	// Layout is the path of the file of an entry relative to Dir, where
+	// <name> is the stream label or, failing that, the structured metadata
+	// name of the entry, or "unknown". Defaults to DefaultFileLayout.
+	Layout string
+	// MaxSize rotates a file before it exceeds MaxSize bytes; 0 disables
+	// size-based rotation.
+	MaxSize int64
+	// Interval rotates a file when an entry timestamp is in a later
+	// interval than the previous entries, e.g. every hour of log time; 0
+	// disables time-based rotation.
+	Interval time.Duration
+	// CopyTruncate rotates by copying a file and truncating it in place, as
+	// logrotate's copytruncate, instead of renaming it and creating a new one.
+	CopyTruncate bool
+	// Compress gzips rotated files.
+	Compress bool
+	// MaxBackups is the number of rotated files kept per file, 0 to keep all.
+	MaxBackups int
+	// MaxOpenFiles is the number of files kept open: writing to another
+	// file closes the least recently written one, which is opened again on
+	// its next entry. Defaults to DefaultMaxOpenFiles.
+	MaxOpenFiles int
+	// Encode returns the text written for an entry, by default its message
+	// and a newline.
+	Encode func(labels model.LabelSet, timestamp time.Time, message string) string
+}
+
+// FileLogger implements the Logger interface and writes the lines of every
+// stream to its own file, rotated by size or time, for testing collectors
+// tailing files. Rotated files are numbered as logrotate's: pod.log.1 is the
+// most recent, pod.log.2 the one before, with a .gz suffix when compressed.
+type FileLogger struct {
+	cfg FileLoggerConfig
+
+	mu    sync.Mutex
+	files map[string]*rotatedFile
+	// open are the open files, most recently written first.
+	open *list.List
+}
+
+// rotatedFile is a file of a FileLogger.
+type rotatedFile struct {
+	path string
+	// file is nil while the file is closed to stay under MaxOpenFiles.
+	file   *os.File
+	size   int64
+	period time.Time
+	// elem is the element of the file in FileLogger.open while it is open.
+	elem *list.Element
+}
+
+// fileLayoutPlaceholder matches the <name> placeholders of a layout.
+var fileLayoutPlaceholder = regexp.MustCompile(`<([a-zA-Z_][a-zA-Z0-9_]*)>`)
+
+// NewFileLogger creates a logger that writes to files under cfg.Dir.
+func NewFileLogger(cfg FileLoggerConfig) (*FileLogger, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("file logger: no directory")
+	}
+	if cfg.Layout == "" {
+		cfg.Layout = DefaultFileLayout
+	}
+	if filepath.IsAbs(cfg.Layout) || !fileLayoutPlaceholder.MatchString(cfg.Layout) {
+		return nil, fmt.Errorf("file logger: layout %q must be a relative path with <label> placeholders", cfg.Layout)
+	}
+	if cfg.MaxSize < 0 || cfg.Interval < 0 || cfg.MaxBackups < 0 || cfg.MaxOpenFiles < 0 {
+		return nil, errors.New("file logger: max size, interval, max backups and max open files cannot be negative")
+	}
+	if cfg.MaxOpenFiles == 0 {
+		cfg.MaxOpenFiles = DefaultMaxOpenFiles
+	}
+	return &FileLogger{cfg: cfg, files: map[string]*rotatedFile{}, open: list.New()}, nil
+}
+
+// Handle implements the Logger interface
+func (l *FileLogger) Handle(labels model.LabelSet, timestamp time.Time, message string) error {
+	return l.HandleWithMetadata(labels, timestamp, message, nil)
+}
+
+// HandleWithMetadata implements the Logger interface
+func (l *FileLogger) HandleWithMetadata(labels model.LabelSet, timestamp time.Time, message string, metadata push.LabelsAdapter) error {
+	path := l.Path(labels, metadata)
+	line := message + "\n"
+	if l.cfg.Encode != nil {
+		line = l.cfg.Encode(labels, timestamp, message)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := l.file(path)
+	if err != nil {
+		return err
+	}
+	if l.due(f, timestamp, len(line)) {
+		if err := l.rotate(f); err != nil {
+			return err
+		}
+	}
+	if l.cfg.Interval > 0 {
+		if period := timestamp.Truncate(l.cfg.Interval); period.After(f.period) {
+			f.period = period
+		}
+	}
+	n, err := f.file.WriteString(line)
+	f.size += int64(n)
+	return err
+}
+
+// Path returns the path of the file of an entry.
+func (l *FileLogger) Path(labels model.LabelSet, metadata push.LabelsAdapter) string {
+	rel := fileLayoutPlaceholder.ReplaceAllStringFunc(l.cfg.Layout, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value := string(labels[model.LabelName(name)])
+		if value == "" {
+			value = MetadataValue(metadata, name)
+		}
+		return filePathElement(value)
+	})
+	return filepath.Join(l.cfg.Dir, filepath.FromSlash(rel))
+}
+
+// filePathElement makes a label value a single path element.
+func filePathElement(value string) string {
+	switch value {
+	case "":
+		return "unknown"
+	case ".", "..":
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// file returns the file of path, opened and most recently written, creating
+// it and its directory.
+func (l *FileLogger) file(path string) (*rotatedFile, error) {
+	f, ok := l.files[path]
+	if ok && f.file != nil {
+		l.open.MoveToFront(f.elem)
+		return f, nil
+	}
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		f = &rotatedFile{path: path}
+	}
+	if err := l.openFile(f); err != nil {
+		return nil, err
+	}
+	l.files[path] = f
+	return f, nil
+}
+
+// openFile opens f, closing the least recently written files beyond
+// MaxOpenFiles.
+func (l *FileLogger) openFile(f *rotatedFile) error {
+	for l.open.Len() >= l.cfg.MaxOpenFiles {
+		if err := l.closeFile(l.open.Back().Value.(*rotatedFile)); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	f.elem = l.open.PushFront(f)
+	return nil
+}
+
+// closeFile closes f until its next entry.
+func (l *FileLogger) closeFile(f *rotatedFile) error {
+	l.open.Remove(f.elem)
+	file := f.file
+	f.file, f.elem = nil, nil
+	return file.Close()
+}
+
+// due reports whether f must be rotated before writing n bytes at t.
+func (l *FileLogger) due(f *rotatedFile, t time.Time, n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if l.cfg.MaxSize > 0 && f.size+int64(n) > l.cfg.MaxSize {
+		return true
+	}
+	return l.cfg.Interval > 0 && !f.period.IsZero() && t.Truncate(l.cfg.Interval).After(f.period)
+}
+
+// rotate moves the content of f to its first backup, shifting the others.
+func (l *FileLogger) rotate(f *rotatedFile) error {
+	if err := l.shiftBackups(f.path); err != nil {
+		return err
+	}
+	if l.cfg.CopyTruncate {
+		if err := l.copyTo(f.path, l.backupName(f.path, 1)); err != nil {
+			return err
+		}
+		if err := f.file.Truncate(0); err != nil {
+			return err
+		}
+		f.size = 0
+		return nil
+	}
+
+	if err := l.closeFile(f); err != nil {
+		return err
+	}
+	renamed := f.path + ".1"
+	if err := os.Rename(f.path, renamed); err != nil {
+		return err
+	}
+	if err := l.openFile(f); err != nil {
+		return err
+	}
+	if l.cfg.Compress {
+		if err := l.copyTo(renamed, l.backupName(f.path, 1)); err != nil {
+			return err
+		}
+		return os.Remove(renamed)
+	}
+	return nil
+}
+
+// shiftBackups renames the backups of path to the next number, dropping the
+// ones beyond MaxBackups.
+func (l *FileLogger) shiftBackups(path string) error {
+	last := 1
+	for ; l.cfg.MaxBackups == 0 || last <= l.cfg.MaxBackups; last++ {
+		if _, err := os.Stat(l.backupName(path, last)); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	if l.cfg.MaxBackups > 0 && last > l.cfg.MaxBackups {
+		last = l.cfg.MaxBackups
+		if err := os.Remove(l.backupName(path, last)); err != nil {
+			return err
+		}
+	}
+	for i := last - 1; i >= 1; i-- {
+		if err := os.Rename(l.backupName(path, i), l.backupName(path, i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupName returns the name of the i-th backup of path.
+func (l *FileLogger) backupName(path string, i int) string {
+	name := fmt.Sprintf("%s.%d", path, i)
+	if l.cfg.Compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// copyTo copies src to dst, gzipped when compressing.
+func (l *FileLogger) copyTo(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	var w io.Writer = out
+	if l.cfg.Compress {
+		gz := gzip.NewWriter(out)
+		defer func() {
+			if closeErr := gz.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = gz
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// Close closes the open files.
+func (l *FileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var errs []error
+	for l.open.Len() > 0 {
+		errs = append(errs, l.closeFile(l.open.Front().Value.(*rotatedFile)))
+	}
+	clear(l.files)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,179 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fileLoggerStart = time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+
+func newTestFileLogger(t *testing.T, cfg FileLoggerConfig) *FileLogger {
+	t.Helper()
+	cfg.Dir = t.TempDir()
+	l, err := NewFileLogger(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, l.Close()) })
+	return l
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = gz
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestFileLoggerImplementsLoggerInterface(t *testing.T) {
+	var _ Logger = &FileLogger{}
+}
+
+func TestFileLoggerLayout(t *testing.T) {
+	l := newTestFileLogger(t, FileLoggerConfig{})
+	labels := model.LabelSet{"namespace": "mimir", "service_name": "mimir-ingester"}
+	pod := func(name string) push.LabelsAdapter { return push.LabelsAdapter{{Name: "pod", Value: name}} }
+
+	require.NoError(t, l.HandleWithMetadata(labels, fileLoggerStart, "first", pod("ingester-0")))
+	require.NoError(t, l.HandleWithMetadata(labels, fileLoggerStart, "second", pod("ingester-0")))
+	require.NoError(t, l.HandleWithMetadata(labels, fileLoggerStart, "other pod", pod("ingester-1")))
+	require.NoError(t, l.Handle(model.LabelSet{"namespace": "..", "service_name": "a/b"}, fileLoggerStart, "no pod"))
+
+	assert.Equal(t, "first\nsecond\n", readFile(t, filepath.Join(l.cfg.Dir, "mimir", "mimir-ingester", "ingester-0.log")))
+	assert.Equal(t, "other pod\n", readFile(t, filepath.Join(l.cfg.Dir, "mimir", "mimir-ingester", "ingester-1.log")))
+	assert.Equal(t, "no pod\n", readFile(t, filepath.Join(l.cfg.Dir, "_", "a_b", "unknown.log")))
+}
+
+func TestNewFileLoggerInvalid(t *testing.T) {
+	for _, cfg := range []FileLoggerConfig{
+		{},
+		{Dir: "logs", Layout: "/var/log/<pod>.log"},
+		{Dir: "logs", Layout: "static.log"},
+		{Dir: "logs", MaxSize: -1},
+	} {
+		_, err := NewFileLogger(cfg)
+		assert.Error(t, err, cfg)
+	}
+}
+
+func TestFileLoggerRotateBySize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  FileLoggerConfig
+	}{
+		{"rename", FileLoggerConfig{Layout: "<service_name>.log", MaxSize: 14, MaxBackups: 2}},
+		{"copytruncate", FileLoggerConfig{Layout: "<service_name>.log", MaxSize: 14, MaxBackups: 2, CopyTruncate: true}},
+		{"rename compressed", FileLoggerConfig{Layout: "<service_name>.log", MaxSize: 14, MaxBackups: 2, Compress: true}},
+		{"copytruncate compressed", FileLoggerConfig{Layout: "<service_name>.log", MaxSize: 14, MaxBackups: 2, CopyTruncate: true, Compress: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newTestFileLogger(t, tc.cfg)
+			labels := model.LabelSet{"service_name": "app"}
+			path := filepath.Join(l.cfg.Dir, "app.log")
+			handle := func(msg string) {
+				require.NoError(t, l.Handle(labels, fileLoggerStart, msg))
+			}
+			handle("line 1")
+			before, err := os.Stat(path)
+			require.NoError(t, err)
+			handle("line 2")
+			handle("line 3")
+			after, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.cfg.CopyTruncate, os.SameFile(before, after), "copytruncate keeps the file, rename creates one")
+			for _, msg := range []string{"line 4", "line 5", "line 6", "line 7"} {
+				handle(msg)
+			}
+
+			suffix := ""
+			if tc.cfg.Compress {
+				suffix = ".gz"
+			}
+			assert.Equal(t, "line 7\n", readFile(t, path))
+			assert.Equal(t, "line 5\nline 6\n", readFile(t, path+".1"+suffix))
+			assert.Equal(t, "line 3\nline 4\n", readFile(t, path+".2"+suffix))
+			assert.NoFileExists(t, path+".3"+suffix, "beyond max backups")
+			if tc.cfg.Compress {
+				assert.NoFileExists(t, path+".1", "compressed backups replace the renamed file")
+			}
+		})
+	}
+}
+
+func TestFileLoggerRotateByInterval(t *testing.T) {
+	l := newTestFileLogger(t, FileLoggerConfig{Layout: "<service_name>.log", Interval: time.Hour})
+	labels := model.LabelSet{"service_name": "app"}
+	path := filepath.Join(l.cfg.Dir, "app.log")
+
+	for _, e := range []struct {
+		offset time.Duration
+		msg    string
+	}{
+		{10 * time.Minute, "a"},
+		{50 * time.Minute, "b"},
+		{70 * time.Minute, "c"},
+		{55 * time.Minute, "late"},
+		{3 * time.Hour, "d"},
+	} {
+		require.NoError(t, l.Handle(labels, fileLoggerStart.Add(e.offset), e.msg))
+	}
+	assert.Equal(t, "d\n", readFile(t, path))
+	assert.Equal(t, "c\nlate\n", readFile(t, path+".1"), "late entries do not rotate back")
+	assert.Equal(t, "a\nb\n", readFile(t, path+".2"))
+}
+
+func TestFileLoggerAppendsToExistingFiles(t *testing.T) {
+	cfg := FileLoggerConfig{Dir: t.TempDir(), Layout: "<service_name>.log", MaxSize: 10}
+	path := filepath.Join(cfg.Dir, "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("previous\n"), 0o644))
+
+	l, err := NewFileLogger(cfg)
+	require.NoError(t, err)
+	require.NoError(t, l.Handle(model.LabelSet{"service_name": "app"}, fileLoggerStart, "next"))
+	require.NoError(t, l.Close())
+
+	assert.Equal(t, "next\n", readFile(t, path))
+	assert.Equal(t, "previous\n", readFile(t, path+".1"))
+}
+
+func TestFileLoggerMaxOpenFiles(t *testing.T) {
+	l := newTestFileLogger(t, FileLoggerConfig{Layout: "<service_name>.log", MaxSize: 14, MaxOpenFiles: 2})
+	services := []model.LabelValue{"a", "b", "c"}
+	for i := 0; i < 4; i++ {
+		for _, svc := range services {
+			require.NoError(t, l.Handle(model.LabelSet{"service_name": svc}, fileLoggerStart, "line "+string(rune('0'+i))))
+			assert.LessOrEqual(t, l.open.Len(), 2)
+		}
+	}
+	assert.Len(t, l.files, 3)
+	assert.Nil(t, l.files[filepath.Join(l.cfg.Dir, "a.log")].file, "the least recently written file is closed")
+
+	for _, svc := range services {
+		path := filepath.Join(l.cfg.Dir, string(svc)+".log")
+		assert.Equal(t, "line 2\nline 3\n", readFile(t, path), "closed files are reopened and keep rotating by size")
+		assert.Equal(t, "line 0\nline 1\n", readFile(t, path+".1"))
+	}
+}
+
+func TestFileLoggerNoIntervalKeepsNoPeriod(t *testing.T) {
+	l := newTestFileLogger(t, FileLoggerConfig{Layout: "<service_name>.log"})
+	require.NoError(t, l.Handle(model.LabelSet{"service_name": "app"}, fileLoggerStart, "a"))
+	assert.True(t, l.files[filepath.Join(l.cfg.Dir, "app.log")].period.IsZero())
+}
@@ -0,0 +1,190 @@
+package log
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/trace"
+	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// FlowHop is one service handling a request of a Flow, along with the
+// services it calls, in order.
+type FlowHop struct {
+	Namespace model.LabelValue
+	Service   model.LabelValue
+	Operation string
+	// Count is how many times the parent calls this hop, e.g. one call per
+	// replica or per query shard. Zero means once.
+	Count int
+	Calls []FlowHop
+}
+
+// Flow is a call graph a request goes through, starting at Root.
+type Flow struct {
+	Name string
+	Root FlowHop
+}
+
+// Services returns the namespace and service of every hop of the flow.
+func (f Flow) Services() [][2]model.LabelValue {
+	var out [][2]model.LabelValue
+	var walk func(FlowHop)
+	walk = func(h FlowHop) {
+		out = append(out, [2]model.LabelValue{h.Namespace, h.Service})
+		for _, c := range h.Calls {
+			walk(c)
+		}
+	}
+	walk(f.Root)
+	return out
+}
+
+// FlowLine is the line a hop logs when a request enters or leaves it. It
+// returns the level and the line; an empty line logs nothing.
+type FlowLine func(hop FlowHop, pod *Pod, t time.Time, event FlowEvent) (model.LabelValue, string)
+
+// FlowEvent describes a request entering (Done is false) or leaving a hop.
+type FlowEvent struct {
+	TraceID  string
+	SpanID   string
+	Done     bool
+	Status   int
+	Duration time.Duration
+}
+
+// FlowSimulator emits the log lines of every hop of a Flow with a shared
+// trace ID, and the matching parent/child spans through a trace.Emitter.
+type FlowSimulator struct {
+	flow     Flow
+	emitter  *trace.Emitter
+	line     FlowLine
+	loggerFn func(pod *Pod) *AppLogger
+	loggers  map[string]*AppLogger
+	// failureRate is the probability of a leaf call failing.
+	failureRate float64
+}
+
+// NewFlowSimulator creates a simulator for flow. loggerFn returns the
+// AppLogger a pod logs to, or nil to drop the lines of that pod; it is called
+// once per pod. emitter may be nil, in which case trace IDs are generated
+// without exporting spans.
+func NewFlowSimulator(flow Flow, emitter *trace.Emitter, line FlowLine, loggerFn func(pod *Pod) *AppLogger) *FlowSimulator {
+	return &FlowSimulator{
+		flow:        flow,
+		emitter:     emitter,
+		line:        line,
+		loggerFn:    loggerFn,
+		loggers:     map[string]*AppLogger{},
+		failureRate: 0.05,
+	}
+}
+
+// Start schedules a request through the flow on every line of the root
+// service's first pod.
+func (f *FlowSimulator) Start() {
+	pods := DefaultTopology.Pods(f.flow.Root.Namespace, f.flow.Root.Service)
+	if len(pods) == 0 {
+		return
+	}
+	root := f.logger(pods[0])
+	if root == nil {
+		return
+	}
+	root.Schedule(func(t time.Time) {
+		f.Request(t)
+	})
+}
+
+// Request sends one request through the flow starting at t and returns its
+// trace ID, as logged by its hops.
+func (f *FlowSimulator) Request(t time.Time) string {
+	traceID, _, _ := f.hop(context.Background(), f.flow.Root, t, randHex(16))
+	return traceID
+}
+
+// hop simulates a request to h starting at start and returns the trace ID
+// it logged, which is the one of its span when spans are emitted, when it
+// completes and whether it failed.
+func (f *FlowSimulator) hop(ctx context.Context, h FlowHop, start time.Time, traceID string) (string, time.Time, bool) {
+	pods := DefaultTopology.Pods(h.Namespace, h.Service)
+	if len(pods) == 0 {
+		return traceID, start, false
+	}
+	pod := DefaultTopology.PodAt(pods[rand.Intn(len(pods))], start)
+
+	parent := oteltrace.SpanContextFromContext(ctx)
+	ctx, span := f.emitter.StartSpan(ctx, string(h.Service), h.Operation, start, pod.Labels())
+	spanID := randHex(8)
+	if sc := span.SpanContext(); sc.IsValid() {
+		traceID = sc.TraceID().String()
+		// Hops without a span of their own share the context of their
+		// parent's but still log a span ID of their own.
+		if sc.SpanID() != parent.SpanID() {
+			spanID = sc.SpanID().String()
+		}
+	}
+
+	f.log(h, pod, start, FlowEvent{TraceID: traceID, SpanID: spanID})
+
+	// Half of the hop's own latency is spent before calling downstream
+	// services, the other half after.
+	latency := time.Duration(1+rand.Intn(20)) * time.Millisecond
+	t := start.Add(latency / 2)
+	failed := len(h.Calls) == 0 && rand.Float64() < f.failureRate
+	for _, call := range h.Calls {
+		for i := 0; i < max(call.Count, 1); i++ {
+			var childFailed bool
+			_, t, childFailed = f.hop(ctx, call, t, traceID)
+			failed = failed || childFailed
+		}
+	}
+	end := t.Add(latency / 2)
+
+	status := 200
+	if failed {
+		status = 500
+		if len(h.Calls) > 0 {
+			status = 502
+		}
+		span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+	}
+	span.End(oteltrace.WithTimestamp(end))
+
+	f.log(h, pod, end, FlowEvent{TraceID: traceID, SpanID: spanID, Done: true, Status: status, Duration: end.Sub(start)})
+	return traceID, end, failed
+}
+
+func (f *FlowSimulator) log(h FlowHop, pod *Pod, t time.Time, event FlowEvent) {
+	logger := f.logger(pod)
+	if logger == nil {
+		return
+	}
+	level, line := f.line(h, pod, t, event)
+	if line == "" {
+		return
+	}
+	logger.LogWithMetadata(level, t, line, MetadataWithTraceID(pod.Metadata(), event.TraceID))
+}
+
+func (f *FlowSimulator) logger(pod *Pod) *AppLogger {
+	if l, ok := f.loggers[pod.Name]; ok {
+		return l
+	}
+	l := f.loggerFn(pod)
+	f.loggers[pod.Name] = l
+	return l
+}
+
+func randHex(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rand.Intn(256))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestFlowSimulatorSharesTraceID(t *testing.T) {
+	type entry struct {
+		service  model.LabelValue
+		ts       time.Time
+		line     string
+		metadata push.LabelsAdapter
+	}
+	var entries []entry
+	capture := LoggerFunc(func(labels model.LabelSet, ts time.Time, message string, metadata push.LabelsAdapter) error {
+		entries = append(entries, entry{labels["service_name"], ts, message, metadata})
+		return nil
+	})
+
+	flow := Flow{Name: "test", Root: FlowHop{
+		Namespace: "gateway", Service: "nginx", Operation: "GET /",
+		Calls: []FlowHop{{
+			Namespace: "mimir-dev", Service: "mimir-distributor", Operation: "Push",
+			Calls: []FlowHop{{Namespace: "mimir-dev", Service: "mimir-ingester", Operation: "Push", Count: 3}},
+		}},
+	}}
+	line := func(hop FlowHop, pod *Pod, ts time.Time, event FlowEvent) (model.LabelValue, string) {
+		return INFO, fmt.Sprintf("%s done=%t trace=%s span=%s", hop.Operation, event.Done, event.TraceID, event.SpanID)
+	}
+	sim := NewFlowSimulator(flow, nil, line, func(pod *Pod) *AppLogger {
+		return NewAppLogger(pod.Labels(), capture)
+	})
+
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	traceID := sim.Request(start)
+	require.Len(t, traceID, 32)
+
+	// Entry and exit lines for nginx, the distributor and three ingester calls.
+	require.Len(t, entries, 10)
+	assert.Equal(t, model.LabelValue("nginx"), entries[0].service)
+	assert.Equal(t, start, entries[0].ts)
+	for _, e := range entries {
+		assert.Contains(t, e.line, "trace="+traceID)
+		assert.Equal(t, traceID, MetadataValue(e.metadata, "traceID"))
+	}
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].ts.Before(entries[i-1].ts), "hops are logged in time order")
+	}
+	// The root completes last, after all of its calls.
+	assert.Equal(t, model.LabelValue("nginx"), entries[len(entries)-1].service)
+}
+
+func TestFlowSimulatorRunsOnScheduler(t *testing.T) {
+	var lines int
+	capture := LoggerFunc(func(model.LabelSet, time.Time, string, push.LabelsAdapter) error {
+		lines++
+		return nil
+	})
+	s := NewScheduler()
+	flow := Flow{Name: "single", Root: FlowHop{Namespace: "gateway", Service: "apache", Operation: "GET /"}}
+	line := func(FlowHop, *Pod, time.Time, FlowEvent) (model.LabelValue, string) { return INFO, "line" }
+	NewFlowSimulator(flow, nil, line, func(pod *Pod) *AppLogger {
+		app := NewAppLogger(pod.Labels(), capture)
+		app.SetScheduler(s)
+		app.SetInterval(func() time.Duration { return time.Millisecond })
+		return app
+	}).Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx, 1)
+	assert.Greater(t, lines, 2)
+}
+
+func TestFlowSimulatorChildWithoutSpanKeepsParentSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent", oteltrace.WithTimestamp(start))
+
+	var events []FlowEvent
+	line := func(_ FlowHop, _ *Pod, _ time.Time, event FlowEvent) (model.LabelValue, string) {
+		events = append(events, event)
+		return INFO, "line"
+	}
+	// Without an emitter connection, no service has a provider.
+	sim := NewFlowSimulator(Flow{}, nil, line, func(pod *Pod) *AppLogger {
+		return NewAppLogger(pod.Labels(), discardLogger())
+	})
+	traceID, end, _ := sim.hop(ctx, FlowHop{Namespace: "mimir-dev", Service: "mimir-ingester", Operation: "Push"}, start, randHex(16))
+
+	assert.True(t, parent.IsRecording(), "the child hop does not end its parent")
+	parentEnd := end.Add(time.Second)
+	parent.End(oteltrace.WithTimestamp(parentEnd))
+
+	sc := parent.SpanContext()
+	assert.Equal(t, sc.TraceID().String(), traceID)
+	require.Len(t, events, 2)
+	for _, e := range events {
+		assert.Equal(t, sc.TraceID().String(), e.TraceID)
+		assert.NotEqual(t, sc.SpanID().String(), e.SpanID, "the child logs a span ID of its own")
+	}
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, parentEnd, ended[0].EndTime())
+}
@@ -0,0 +1,330 @@
+package log
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultFluentTag is the tag of FluentLogger entries unless configured
+// otherwise.
+const DefaultFluentTag = "generator.<namespace>.<service_name>"
+
+// FluentLoggerConfig configures a FluentLogger.
+type FluentLoggerConfig struct {
+	// Addr is the host:port of the forward input, e.g. localhost:24224.
+	Addr string
+	// Tag is the tag of an entry, where <name> is its stream label or, failing
+	// that, its structured metadata, or "unknown". Defaults to
+	// DefaultFluentTag.
+	Tag string
+	// RequireAck asks the receiver to acknowledge every chunk, which is sent
+	// again when it is not acknowledged in time.
+	RequireAck bool
+	BatchWait  time.Duration
+	BatchSize  int
+	// Timeout bounds connecting, writing a chunk and waiting for its ack.
+	Timeout    time.Duration
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// FluentLogger sends logs to Fluent Bit or Fluentd with the Fluent Forward
+// protocol over TCP, see
+// https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1.
+// Entries are batched per tag in PackedForward messages, with their time as
+// EventTime and a record of their line as "log", their stream labels and
+// their structured metadata.
+type FluentLogger struct {
+	cfg     FluentLoggerConfig
+	quit    chan struct{}
+	once    sync.Once
+	entries chan fluentEntry
+	batches chan *fluentBatch
+	wg      sync.WaitGroup
+
+	// conn and its reader are only used by the sender.
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+type fluentEntry struct {
+	tag string
+	// event is the msgpack [time, record] of the entry.
+	event []byte
+}
+
+// NewFluentLogger creates a FluentLogger and starts its background batch
+// sender. It connects on the first batch.
+func NewFluentLogger(cfg FluentLoggerConfig) (*FluentLogger, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("fluent logger: address is required")
+	}
+	if cfg.Tag == "" {
+		cfg.Tag = DefaultFluentTag
+	}
+	if cfg.BatchWait <= 0 {
+		cfg.BatchWait = defaultBatchWait
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	l := &FluentLogger{
+		cfg:     cfg,
+		quit:    make(chan struct{}),
+		entries: make(chan fluentEntry),
+		batches: make(chan *fluentBatch, 1),
+	}
+	l.wg.Add(2)
+	go func() {
+		defer l.wg.Done()
+		for batch := range l.batches {
+			l.sendBatch(batch)
+		}
+		if l.conn != nil {
+			l.conn.Close()
+		}
+	}()
+	go l.run()
+	return l, nil
+}
+
+// run collects entries into batches and hands full or old batches to the
+// sender.
+func (l *FluentLogger) run() {
+	var batch *fluentBatch
+
+	maxWaitCheckFrequency := l.cfg.BatchWait / 10
+	if maxWaitCheckFrequency < 10*time.Millisecond {
+		maxWaitCheckFrequency = 10 * time.Millisecond
+	}
+	maxWaitCheck := time.NewTicker(maxWaitCheckFrequency)
+	defer maxWaitCheck.Stop()
+
+	defer func() {
+		if batch != nil {
+			l.batches <- batch
+		}
+		close(l.batches)
+		l.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-l.quit:
+			return
+
+		case e := <-l.entries:
+			if batch == nil {
+				batch = newFluentBatch()
+			} else if batch.bytes+len(e.event) > l.cfg.BatchSize {
+				l.batches <- batch
+				batch = newFluentBatch()
+			}
+			batch.add(e)
+
+		case <-maxWaitCheck.C:
+			if batch != nil && time.Since(batch.createdAt) >= l.cfg.BatchWait {
+				l.batches <- batch
+				batch = nil
+			}
+		}
+	}
+}
+
+// fluentBatch is the events of a batch by tag.
+type fluentBatch struct {
+	tags      map[string]*fluentChunk
+	bytes     int
+	createdAt time.Time
+}
+
+// fluentChunk is the concatenated events of a tag, the entries of a
+// PackedForward message.
+type fluentChunk struct {
+	events []byte
+	size   int
+}
+
+func newFluentBatch() *fluentBatch {
+	return &fluentBatch{tags: map[string]*fluentChunk{}, createdAt: time.Now()}
+}
+
+func (b *fluentBatch) add(e fluentEntry) {
+	chunk, ok := b.tags[e.tag]
+	if !ok {
+		chunk = &fluentChunk{}
+		b.tags[e.tag] = chunk
+	}
+	chunk.events = append(chunk.events, e.event...)
+	chunk.size++
+	b.bytes += len(e.event)
+}
+
+// sendBatch sends a message per tag, in tag order, retrying with backoff.
+// Messages still failing after MaxRetries are dropped.
+func (l *FluentLogger) sendBatch(batch *fluentBatch) {
+	tags := make([]string, 0, len(batch.tags))
+	for tag := range batch.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		msg, chunkID := l.encode(tag, batch.tags[tag])
+		backoff := l.cfg.MinBackoff
+		for attempt := 0; attempt <= l.cfg.MaxRetries; attempt++ {
+			err := l.send(msg, chunkID)
+			if err == nil || attempt == l.cfg.MaxRetries {
+				break
+			}
+			if l.conn != nil {
+				l.conn.Close()
+				l.conn = nil
+			}
+			time.Sleep(backoff)
+			if backoff < l.cfg.MaxBackoff {
+				backoff *= 2
+				if backoff > l.cfg.MaxBackoff {
+					backoff = l.cfg.MaxBackoff
+				}
+			}
+		}
+	}
+}
+
+// encode returns the PackedForward message of chunk, and its chunk ID when
+// an ack is required.
+func (l *FluentLogger) encode(tag string, chunk *fluentChunk) ([]byte, string) {
+	var chunkID string
+	options := 1
+	if l.cfg.RequireAck {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		chunkID = base64.StdEncoding.EncodeToString(id)
+		options++
+	}
+	msg := appendMsgpackArray(nil, 3)
+	msg = appendMsgpackString(msg, tag)
+	msg = appendMsgpackBin(msg, chunk.events)
+	msg = appendMsgpackMap(msg, options)
+	msg = appendMsgpackString(msg, "size")
+	msg = appendMsgpackUint(msg, uint64(chunk.size))
+	if chunkID != "" {
+		msg = appendMsgpackString(msg, "chunk")
+		msg = appendMsgpackString(msg, chunkID)
+	}
+	return msg, chunkID
+}
+
+// send writes msg, connecting first if needed, and waits for the ack of
+// chunkID if not empty.
+func (l *FluentLogger) send(msg []byte, chunkID string) error {
+	if l.conn == nil {
+		conn, err := net.DialTimeout("tcp", l.cfg.Addr, l.cfg.Timeout)
+		if err != nil {
+			return err
+		}
+		l.conn, l.reader = conn, bufio.NewReader(conn)
+	}
+	if err := l.conn.SetDeadline(time.Now().Add(l.cfg.Timeout)); err != nil {
+		return err
+	}
+	if _, err := l.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunkID == "" {
+		return nil
+	}
+	resp, err := decodeMsgpack(l.reader)
+	if err != nil {
+		return err
+	}
+	if m, ok := resp.(map[string]any); !ok || m["ack"] != chunkID {
+		return fmt.Errorf("fluent forward: expected the ack of chunk %s, got %v", chunkID, resp)
+	}
+	return nil
+}
+
+// Stop flushes pending batches and closes the connection.
+func (l *FluentLogger) Stop() {
+	l.once.Do(func() { close(l.quit) })
+	l.wg.Wait()
+}
+
+// Handle implements Logger.
+func (l *FluentLogger) Handle(labels model.LabelSet, t time.Time, msg string) error {
+	return l.HandleWithMetadata(labels, t, msg, nil)
+}
+
+// HandleWithMetadata implements Logger.
+func (l *FluentLogger) HandleWithMetadata(labels model.LabelSet, t time.Time, msg string, md push.LabelsAdapter) error {
+	tag := fileLayoutPlaceholder.ReplaceAllStringFunc(l.cfg.Tag, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if value := labels[model.LabelName(name)]; value != "" {
+			return string(value)
+		}
+		if value := MetadataValue(md, name); value != "" {
+			return value
+		}
+		return "unknown"
+	})
+	l.entries <- fluentEntry{tag: tag, event: fluentEvent(labels, t, msg, md)}
+	return nil
+}
+
+// fluentEvent returns the msgpack [time, record] of an entry.
+func fluentEvent(labels model.LabelSet, t time.Time, msg string, md push.LabelsAdapter) []byte {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if len(name) < 2 || name[:2] != "__" {
+			names = append(names, string(name))
+		}
+	}
+	sort.Strings(names)
+	// Structured metadata named as a label or "log" would duplicate record
+	// keys, which Fluent Bit keeps.
+	var metadata push.LabelsAdapter
+	for _, l := range md {
+		if _, ok := labels[model.LabelName(l.Name)]; !ok && l.Name != "log" {
+			metadata = append(metadata, l)
+		}
+	}
+
+	event := appendMsgpackArray(nil, 2)
+	event = appendEventTime(event, t)
+	event = appendMsgpackMap(event, 1+len(names)+len(metadata))
+	event = appendMsgpackString(event, "log")
+	event = appendMsgpackString(event, msg)
+	for _, name := range names {
+		event = appendMsgpackString(event, name)
+		event = appendMsgpackString(event, string(labels[model.LabelName(name)]))
+	}
+	for _, l := range metadata {
+		event = appendMsgpackString(event, l.Name)
+		event = appendMsgpackString(event, l.Value)
+	}
+	return event
+}
@@ -0,0 +1,190 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forwardEvent is an event received by a forwardReceiver.
+type forwardEvent struct {
+	tag    string
+	time   time.Time
+	record map[string]any
+}
+
+// forwardReceiver is an in-process Fluent Forward input, accepting the
+// PackedForward messages of FluentLogger.
+type forwardReceiver struct {
+	t        *testing.T
+	listener net.Listener
+	// dropAcks is the number of chunks received without ack.
+	dropAcks int
+
+	mu       sync.Mutex
+	messages int
+	events   []forwardEvent
+}
+
+func newForwardReceiver(t *testing.T) *forwardReceiver {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := &forwardReceiver{t: t, listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *forwardReceiver) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		v, err := decodeMsgpack(reader)
+		if err != nil {
+			return
+		}
+		msg, ok := v.([]any)
+		if !assert.True(r.t, ok && len(msg) == 3, "PackedForward message: %v", v) {
+			return
+		}
+		tag, entries, option := msg[0].(string), msg[1].([]byte), msg[2].(map[string]any)
+
+		var events []forwardEvent
+		entriesReader := bufio.NewReader(bytes.NewReader(entries))
+		for {
+			v, err := decodeMsgpack(entriesReader)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(r.t, err)
+			event := v.([]any)
+			events = append(events, forwardEvent{tag: tag, time: event[0].(time.Time), record: event[1].(map[string]any)})
+		}
+		assert.EqualValues(r.t, len(events), option["size"])
+
+		r.mu.Lock()
+		r.messages++
+		drop := r.dropAcks > 0 && option["chunk"] != nil
+		if drop {
+			r.dropAcks--
+		} else {
+			r.events = append(r.events, events...)
+		}
+		r.mu.Unlock()
+
+		if chunk, ok := option["chunk"].(string); ok && !drop {
+			ack := appendMsgpackMap(nil, 1)
+			ack = appendMsgpackString(ack, "ack")
+			ack = appendMsgpackString(ack, chunk)
+			if _, err := conn.Write(ack); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// received waits for n events, which the receiver may still be reading
+// when the logger stops without acks.
+func (r *forwardReceiver) received(n int) (messages int, events []forwardEvent) {
+	require.Eventually(r.t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.events) >= n
+	}, 5*time.Second, time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages, append([]forwardEvent(nil), r.events...)
+}
+
+func TestFluentLoggerImplementsLoggerInterface(t *testing.T) {
+	var _ Logger = &FluentLogger{}
+}
+
+func TestFluentLogger(t *testing.T) {
+	for _, ack := range []bool{false, true} {
+		receiver := newForwardReceiver(t)
+		l, err := NewFluentLogger(FluentLoggerConfig{Addr: receiver.listener.Addr().String(), RequireAck: ack, BatchWait: time.Hour})
+		require.NoError(t, err)
+
+		ts := time.Date(2026, 4, 26, 11, 0, 0, 123456789, time.UTC)
+		ingester := model.LabelSet{"namespace": "mimir-prod", "service_name": "mimir-ingester", "level": "info", "__stream_shard__": "1"}
+		nginx := model.LabelSet{"namespace": "gateway", "service_name": "nginx"}
+		md := push.LabelsAdapter{{Name: "pod", Value: "mimir-ingester-0"}, {Name: "level", Value: "dup"}, {Name: "log", Value: "dup"}}
+		require.NoError(t, l.HandleWithMetadata(ingester, ts, "first", md))
+		require.NoError(t, l.Handle(nginx, ts.Add(time.Second), "GET /"))
+		require.NoError(t, l.HandleWithMetadata(ingester, ts.Add(2*time.Second), "second", md))
+		l.Stop()
+
+		messages, events := receiver.received(3)
+		assert.Equal(t, 2, messages, "a message per tag")
+		require.Len(t, events, 3)
+		assert.Equal(t, forwardEvent{tag: "generator.gateway.nginx", time: ts.Add(time.Second), record: map[string]any{
+			"log": "GET /", "namespace": "gateway", "service_name": "nginx",
+		}}, events[0], "tags are sent in order")
+		for i, msg := range []string{"first", "second"} {
+			e := events[1+i]
+			assert.Equal(t, "generator.mimir-prod.mimir-ingester", e.tag)
+			assert.True(t, ts.Add(time.Duration(2*i)*time.Second).Equal(e.time), "event time has nanoseconds")
+			assert.Equal(t, map[string]any{
+				"log": msg, "namespace": "mimir-prod", "service_name": "mimir-ingester", "level": "info", "pod": "mimir-ingester-0",
+			}, e.record)
+		}
+	}
+}
+
+func TestFluentLoggerBatchSize(t *testing.T) {
+	receiver := newForwardReceiver(t)
+	l, err := NewFluentLogger(FluentLoggerConfig{Addr: receiver.listener.Addr().String(), Tag: "<pod>", BatchWait: time.Hour, BatchSize: 100})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.Handle(model.LabelSet{}, time.Now(), "a line of about forty bytes......"))
+	}
+	l.Stop()
+
+	messages, events := receiver.received(10)
+	assert.Len(t, events, 10)
+	assert.Greater(t, messages, 3, "batches stay under the batch size")
+	assert.Equal(t, "unknown", events[0].tag)
+}
+
+func TestFluentLoggerRetriesUnacknowledgedChunks(t *testing.T) {
+	receiver := newForwardReceiver(t)
+	receiver.dropAcks = 1
+	l, err := NewFluentLogger(FluentLoggerConfig{
+		Addr:       receiver.listener.Addr().String(),
+		RequireAck: true,
+		BatchWait:  10 * time.Millisecond,
+		Timeout:    100 * time.Millisecond,
+		MinBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, l.Handle(model.LabelSet{"service_name": "app"}, time.Now(), "line"))
+	l.Stop()
+
+	messages, events := receiver.received(1)
+	assert.Equal(t, 2, messages, "the chunk is sent again")
+	require.Len(t, events, 1)
+	assert.Equal(t, "line", events[0].record["log"])
+}
+
+func TestNewFluentLoggerRequiresAddr(t *testing.T) {
+	_, err := NewFluentLogger(FluentLoggerConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// JournalLogger implements the Logger interface and writes entries in the
+// systemd journal export format, which systemd-journal-remote turns into
+// .journal files, for testing collectors' journal sources without a systemd
+// host. Each entry has:
+//   - MESSAGE, the line;
+//   - PRIORITY, the syslog severity of the level label;
+//   - SYSLOG_IDENTIFIER, the service_name label, and _SYSTEMD_UNIT, the
+//     service_name label with a .service suffix;
+//   - _HOSTNAME, the pod of the structured metadata, or else the hostname;
+//   - the other labels and structured metadata, named in upper case with
+//     characters other than letters, digits and '_' replaced by '_' (pod
+//     becomes POD, traceID TRACEID).
+type JournalLogger struct {
+	hostname string
+
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// NewJournalLogger creates a logger that writes journal export entries to w.
+func NewJournalLogger(w io.Writer) *JournalLogger {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown-host"
+	}
+	return &JournalLogger{hostname: hostname, w: bufio.NewWriter(w)}
+}
+
+// Handle implements the Logger interface
+func (j *JournalLogger) Handle(labels model.LabelSet, timestamp time.Time, message string) error {
+	return j.HandleWithMetadata(labels, timestamp, message, nil)
+}
+
+// HandleWithMetadata implements the Logger interface
+func (j *JournalLogger) HandleWithMetadata(labels model.LabelSet, timestamp time.Time, message string, metadata push.LabelsAdapter) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, field := range j.fields(labels, timestamp, message, metadata) {
+		writeJournalField(j.w, field[0], field[1])
+	}
+	j.w.WriteByte('\n')
+	// Entries are flushed whole, so a reader never sees half an entry.
+	return j.w.Flush()
+}
+
+// fields returns the fields of an entry, in order.
+func (j *JournalLogger) fields(labels model.LabelSet, timestamp time.Time, message string, metadata push.LabelsAdapter) [][2]string {
+	level, ok := labels["level"]
+	if !ok {
+		level = INFO
+	}
+	serviceName, ok := labels["service_name"]
+	if !ok {
+		serviceName = "unknown_service"
+	}
+	hostname := MetadataValue(metadata, "pod")
+	if hostname == "" {
+		hostname = j.hostname
+	}
+	fields := [][2]string{
+		{"__REALTIME_TIMESTAMP", strconv.FormatInt(timestamp.UnixMicro(), 10)},
+		{"_HOSTNAME", hostname},
+		{"_SYSTEMD_UNIT", string(serviceName) + ".service"},
+		{"SYSLOG_IDENTIFIER", string(serviceName)},
+		{"PRIORITY", strconv.Itoa(getSeverityNumber(string(level)))},
+		{"MESSAGE", message},
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if field := JournalFieldName(name); field != "" {
+			fields = append(fields, [2]string{field, string(labels[model.LabelName(name)])})
+		}
+	}
+	for _, l := range metadata {
+		if field := JournalFieldName(l.Name); field != "" {
+			fields = append(fields, [2]string{field, l.Value})
+		}
+	}
+	return fields
+}
+
+// JournalFieldName returns the journal field of a label or structured
+// metadata name: upper case, with characters other than letters, digits and
+// '_' replaced by '_', without leading underscores, which are reserved for
+// fields set by journald, and at most 64 characters. It returns "" for
+// internal labels, whose name starts with "__".
+func JournalFieldName(name string) string {
+	if strings.HasPrefix(name, "__") {
+		return ""
+	}
+	field := []byte(strings.TrimLeft(strings.ToUpper(name), "_"))
+	for i, c := range field {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			field[i] = '_'
+		}
+	}
+	if len(field) > 0 && field[0] >= '0' && field[0] <= '9' {
+		field = append([]byte("F_"), field...)
+	}
+	if len(field) > 64 {
+		field = field[:64]
+	}
+	return string(field)
+}
+
+// writeJournalField writes a field in the export format: NAME=value, or,
+// when value is not printable text, NAME, its length as a little endian
+// uint64 and value.
+func writeJournalField(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	if journalPrintable(value) {
+		w.WriteByte('=')
+		w.WriteString(value)
+		w.WriteByte('\n')
+		return
+	}
+	w.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	w.Write(size[:])
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+// journalPrintable reports whether value can be written as NAME=value:
+// valid UTF-8 without control characters other than tabs.
+func journalPrintable(value string) bool {
+	for _, r := range value {
+		if r < ' ' && r != '\t' || r == 0x7f {
+			return false
+		}
+	}
+	return utf8.ValidString(value)
+}
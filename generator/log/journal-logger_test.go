@@ -0,0 +1,119 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readJournalExport parses journal export entries, as systemd-journal-remote
+// does, into their fields.
+func readJournalExport(t *testing.T, r io.Reader) []map[string][]string {
+	t.Helper()
+	br := bufio.NewReader(r)
+	var entries []map[string][]string
+	entry := map[string][]string{}
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			require.Empty(t, line, "entries end with a newline")
+			require.Empty(t, entry, "entries end with an empty line")
+			return entries
+		}
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			entries = append(entries, entry)
+			entry = map[string][]string{}
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			var size uint64
+			require.NoError(t, binary.Read(br, binary.LittleEndian, &size))
+			b := make([]byte, size+1)
+			_, err := io.ReadFull(br, b)
+			require.NoError(t, err)
+			require.Equal(t, byte('\n'), b[size])
+			value = string(b[:size])
+		}
+		entry[name] = append(entry[name], value)
+	}
+}
+
+func TestJournalLoggerImplementsLoggerInterface(t *testing.T) {
+	var _ Logger = &JournalLogger{}
+}
+
+func TestJournalLogger(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewJournalLogger(&buf)
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 123456789, time.UTC)
+	labels := model.LabelSet{"service_name": "mimir-ingester", "namespace": "mimir-prod", "level": "error", "__stream_shard__": "1", "k8s.cluster": "prod"}
+	metadata := push.LabelsAdapter{{Name: "pod", Value: "mimir-ingester-0"}, {Name: "traceID", Value: "abc"}}
+
+	require.NoError(t, j.HandleWithMetadata(labels, ts, "panic: boom\n\tat main.go:42", metadata))
+	require.NoError(t, j.Handle(model.LabelSet{}, ts.Add(time.Second), "plain"))
+
+	entries := readJournalExport(t, &buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string][]string{
+		"__REALTIME_TIMESTAMP": {"1777201200123456"},
+		"_HOSTNAME":            {"mimir-ingester-0"},
+		"_SYSTEMD_UNIT":        {"mimir-ingester.service"},
+		"SYSLOG_IDENTIFIER":    {"mimir-ingester"},
+		"PRIORITY":             {"3"},
+		"MESSAGE":              {"panic: boom\n\tat main.go:42"},
+		"K8S_CLUSTER":          {"prod"},
+		"LEVEL":                {"error"},
+		"NAMESPACE":            {"mimir-prod"},
+		"SERVICE_NAME":         {"mimir-ingester"},
+		"POD":                  {"mimir-ingester-0"},
+		"TRACEID":              {"abc"},
+	}, entries[0])
+	assert.Equal(t, []string{"6"}, entries[1]["PRIORITY"], "info without level")
+	assert.Equal(t, []string{"unknown_service"}, entries[1]["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, []string{j.hostname}, entries[1]["_HOSTNAME"])
+	assert.Equal(t, []string{"plain"}, entries[1]["MESSAGE"])
+}
+
+func TestJournalLoggerBinaryFields(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewJournalLogger(&buf)
+	require.NoError(t, j.Handle(model.LabelSet{"service_name": "app"}, time.Now(), "a\nb"))
+	assert.Contains(t, buf.String(), "MESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n")
+
+	for value, printable := range map[string]bool{
+		"tab\tseparated": true,
+		"日本語":            true,
+		"line\nbreak":    false,
+		"nul\x00":        false,
+		"del\x7f":        false,
+		"invalid\xff":    false,
+	} {
+		assert.Equal(t, printable, journalPrintable(value), value)
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for name, field := range map[string]string{
+		"service_name":          "SERVICE_NAME",
+		"traceID":               "TRACEID",
+		"k8s.pod-name":          "K8S_POD_NAME",
+		"_private":              "PRIVATE",
+		"__stream_shard__":      "",
+		"2fa":                   "F_2FA",
+		strings.Repeat("a", 80): strings.Repeat("A", 64),
+	} {
+		assert.Equal(t, field, JournalFieldName(name), name)
+	}
+}
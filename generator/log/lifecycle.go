@@ -0,0 +1,197 @@
+package log
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+)
+
+// LifecycleConfig describes how the pods of a deployment change over time.
+// Zero values disable the corresponding behaviour.
+type LifecycleConfig struct {
+	// RolloutInterval is the time between two deployments of the service.
+	// Each rollout creates a new ReplicaSet hash, so every pod gets a new name.
+	RolloutInterval time.Duration
+	// RolloutDuration is how long a rollout takes: replicas are replaced one
+	// after the other over this duration, while old pods drain and stop.
+	RolloutDuration time.Duration
+	// RestartInterval is the time between container restarts of a running pod.
+	RestartInterval time.Duration
+	// CrashLoopReplicas is the number of replicas (starting from index 0) that
+	// crash CrashAfter after every start and come back after an exponential
+	// back-off, like a pod in CrashLoopBackOff.
+	CrashLoopReplicas int
+	CrashAfter        time.Duration
+}
+
+const (
+	crashLoopMinBackoff = 10 * time.Second
+	crashLoopMaxBackoff = 5 * time.Minute
+)
+
+// PodEvent is a change in a pod's lifecycle.
+type PodEvent int
+
+const (
+	// PodStarted is emitted when a pod is first seen, including new pods of a rollout.
+	PodStarted PodEvent = iota + 1
+	// PodStopped is emitted for the old pod when it is replaced by a rollout.
+	PodStopped
+	// PodRestarted is emitted when the container of a pod starts again after a restart or crash.
+	PodRestarted
+	// PodCrashed is emitted when a crash-looping container exits.
+	PodCrashed
+)
+
+func (e PodEvent) String() string {
+	switch e {
+	case PodStarted:
+		return "started"
+	case PodStopped:
+		return "stopped"
+	case PodRestarted:
+		return "restarted"
+	case PodCrashed:
+		return "crashed"
+	}
+	return "unknown"
+}
+
+// PodState is what a pod looks like at a point in time.
+type PodState struct {
+	Name       string
+	Node       string
+	ReplicaSet string
+	Revision   int
+	Restarts   int
+	// Running is false while a crash-looping container is backing off.
+	Running bool
+}
+
+// Metadata returns structured metadata for one entry of the pod in this state.
+func (s PodState) Metadata() push.LabelsAdapter {
+	return append(RandStructuredMetadata(s.Name),
+		push.LabelAdapter{Name: "node", Value: s.Node},
+		push.LabelAdapter{Name: "restarts", Value: strconv.Itoa(s.Restarts)},
+	)
+}
+
+// PodLifecycle follows one replica of a Pod over time. It is advanced with
+// the timestamps the scheduler hands to an emitter, so it behaves the same in
+// live and static mode. Not safe for concurrent use: each emitter owns one.
+type PodLifecycle struct {
+	pod   *Pod
+	cfg   LifecycleConfig
+	phase time.Duration
+
+	started bool
+	state   PodState
+	// Crash loop state of the current revision.
+	revisionStart time.Time
+	nextCrash     time.Time
+	nextStart     time.Time
+	backoff       time.Duration
+}
+
+// NewPodLifecycle returns the lifecycle of pod. Rollouts and restarts happen
+// at times derived from the pod's names, so all pods of a deployment don't
+// restart at once.
+func NewPodLifecycle(pod *Pod, cfg LifecycleConfig) *PodLifecycle {
+	l := &PodLifecycle{pod: pod, cfg: cfg}
+	if cfg.RolloutInterval > 0 {
+		l.phase = time.Duration(hash(pod.Cluster, string(pod.Namespace), string(pod.Service))) % cfg.RolloutInterval
+	}
+	return l
+}
+
+// Advance moves the pod to time t and returns its state along with the
+// events that happened since the previous call. The PodStopped event, if any,
+// refers to the previous state, which is returned as stopped.
+func (l *PodLifecycle) Advance(t time.Time) (state PodState, stopped PodState, events []PodEvent) {
+	revision, revisionStart := l.revision(t)
+	if !l.started || revision != l.state.Revision {
+		if l.started {
+			stopped = l.state
+			events = append(events, PodStopped)
+		}
+		rs := ReplicaSetHash(l.pod.Cluster, l.pod.Namespace, l.pod.Service, revision)
+		pod := newPod(l.pod.Cluster, l.pod.Namespace, l.pod.Service, rs, l.pod.Index)
+		l.state = PodState{
+			Name:       pod.Name,
+			Node:       pod.Node,
+			ReplicaSet: rs,
+			Revision:   revision,
+			Running:    true,
+		}
+		l.revisionStart = revisionStart
+		if !l.started || l.cfg.RolloutInterval <= 0 {
+			// First observation in the middle of a revision.
+			l.revisionStart = t
+		}
+		l.started = true
+		l.backoff = crashLoopMinBackoff
+		l.nextCrash = l.revisionStart.Add(l.crashAfter())
+		l.nextStart = time.Time{}
+		events = append(events, PodStarted)
+	}
+
+	if l.crashLooping() {
+		return l.advanceCrashLoop(t, events, stopped)
+	}
+
+	if l.cfg.RestartInterval > 0 {
+		// Spread restarts of the pods of a deployment between 0.75 and 1.25
+		// times the configured interval.
+		interval := l.cfg.RestartInterval*3/4 + l.cfg.RestartInterval/2*time.Duration(hash(l.state.Name)%100)/100
+		restarts := int(t.Sub(l.revisionStart) / interval)
+		if restarts > l.state.Restarts {
+			l.state.Restarts = restarts
+			events = append(events, PodRestarted)
+		}
+	}
+	return l.state, stopped, events
+}
+
+func (l *PodLifecycle) advanceCrashLoop(t time.Time, events []PodEvent, stopped PodState) (PodState, PodState, []PodEvent) {
+	if l.state.Running && !t.Before(l.nextCrash) {
+		l.state.Running = false
+		l.state.Restarts++
+		l.nextStart = l.nextCrash.Add(l.backoff)
+		l.backoff = min(2*l.backoff, crashLoopMaxBackoff)
+		events = append(events, PodCrashed)
+		return l.state, stopped, events
+	}
+	if !l.state.Running && !t.Before(l.nextStart) {
+		l.state.Running = true
+		l.nextCrash = l.nextStart.Add(l.crashAfter())
+		events = append(events, PodRestarted)
+	}
+	return l.state, stopped, events
+}
+
+func (l *PodLifecycle) crashLooping() bool {
+	return l.pod.Index < l.cfg.CrashLoopReplicas
+}
+
+func (l *PodLifecycle) crashAfter() time.Duration {
+	if l.cfg.CrashAfter > 0 {
+		return l.cfg.CrashAfter
+	}
+	return 30 * time.Second
+}
+
+// revision returns the deployment revision of the pod at t and when that
+// revision replaced this replica. Replicas of a rollout are replaced one
+// after the other over RolloutDuration.
+func (l *PodLifecycle) revision(t time.Time) (int, time.Time) {
+	if l.cfg.RolloutInterval <= 0 {
+		return 0, time.Time{}
+	}
+	replicas := ReplicasForService(l.pod.Namespace, l.pod.Service)
+	offset := l.phase + l.cfg.RolloutDuration*time.Duration(l.pod.Index+1)/time.Duration(replicas+1)
+	since := t.Sub(time.Unix(0, 0)) - offset
+	revision := int(since / l.cfg.RolloutInterval)
+	start := time.Unix(0, 0).Add(offset + time.Duration(revision)*l.cfg.RolloutInterval)
+	return revision, start
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodLifecycleRollout(t *testing.T) {
+	pod := NewTopology().Pods("checkout", "checkout-api")[0]
+	l := NewPodLifecycle(pod, LifecycleConfig{RolloutInterval: 10 * time.Minute, RolloutDuration: time.Minute})
+
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	first, _, events := l.Advance(start)
+	assert.Equal(t, []PodEvent{PodStarted}, events)
+	assert.True(t, first.Running)
+
+	names := map[string]bool{first.Name: true}
+	var stops int
+	for ts := start; ts.Before(start.Add(time.Hour)); ts = ts.Add(10 * time.Second) {
+		state, stopped, events := l.Advance(ts)
+		names[state.Name] = true
+		assert.Equal(t, PodNode(pod.Cluster, state.Name), state.Node, "pods of a new revision are scheduled anew")
+		assert.Equal(t, state.Node, MetadataValue(state.Metadata(), "node"))
+		if len(events) > 0 {
+			require.Equal(t, []PodEvent{PodStopped, PodStarted}, events)
+			assert.NotEqual(t, stopped.Name, state.Name)
+			assert.Equal(t, stopped.Revision+1, state.Revision)
+			stops++
+		}
+	}
+	assert.Equal(t, 6, stops)
+	assert.Len(t, names, 7)
+}
+
+func TestPodLifecycleRestarts(t *testing.T) {
+	pod := NewTopology().Pods("checkout", "inventory-worker")[0]
+	l := NewPodLifecycle(pod, LifecycleConfig{RestartInterval: 10 * time.Minute})
+
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	var restarts int
+	var state PodState
+	for ts := start; ts.Before(start.Add(time.Hour)); ts = ts.Add(10 * time.Second) {
+		var events []PodEvent
+		state, _, events = l.Advance(ts)
+		for _, e := range events {
+			if e == PodRestarted {
+				restarts++
+			}
+		}
+		assert.Equal(t, pod.Name, state.Name, "restarts keep the pod name")
+	}
+	assert.Equal(t, restarts, state.Restarts)
+	assert.GreaterOrEqual(t, restarts, 4)
+	assert.LessOrEqual(t, restarts, 8)
+	assert.Equal(t, "4", MetadataValue(PodState{Name: pod.Name, Restarts: 4}.Metadata(), "restarts"))
+}
+
+func TestPodLifecycleCrashLoopBacksOff(t *testing.T) {
+	pod := NewTopology().Pods("checkout", "payment-gateway")[0]
+	l := NewPodLifecycle(pod, LifecycleConfig{CrashLoopReplicas: 1, CrashAfter: 30 * time.Second})
+
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	var crashes []time.Time
+	var down time.Duration
+	for ts := start; ts.Before(start.Add(time.Hour)); ts = ts.Add(time.Second) {
+		state, _, events := l.Advance(ts)
+		for _, e := range events {
+			if e == PodCrashed {
+				crashes = append(crashes, ts)
+			}
+		}
+		if !state.Running {
+			down += time.Second
+		}
+	}
+	require.Greater(t, len(crashes), 5)
+	// Back-off doubles from 10s and is capped at 5 minutes.
+	assert.Equal(t, 30*time.Second, crashes[0].Sub(start))
+	assert.Equal(t, 10*time.Second+30*time.Second, crashes[1].Sub(crashes[0]))
+	assert.Equal(t, 20*time.Second+30*time.Second, crashes[2].Sub(crashes[1]))
+	assert.Equal(t, crashLoopMaxBackoff+30*time.Second, crashes[len(crashes)-1].Sub(crashes[len(crashes)-2]))
+	assert.Greater(t, down, 30*time.Minute, "a crash-looping pod is down most of the time")
+}
+
+func TestTopologyPodAtFollowsLifecycle(t *testing.T) {
+	topology := NewTopology()
+	pods := topology.Pods("checkout", "payment-gateway")
+	pod := pods[len(pods)-1]
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	assert.Same(t, pod, topology.PodAt(pod, start), "pods without a lifecycle don't change")
+
+	cfg := LifecycleConfig{RolloutInterval: 10 * time.Minute, RolloutDuration: time.Minute}
+	topology.SetLifecycle("checkout", "payment-gateway", cfg)
+	l := NewPodLifecycle(pod, cfg)
+	for ts := start; ts.Before(start.Add(time.Hour)); ts = ts.Add(10 * time.Second) {
+		state, _, _ := l.Advance(ts)
+		current := topology.PodAt(pod, ts)
+		assert.Equal(t, state.Name, current.Name)
+		assert.Equal(t, state.Node, current.Node)
+	}
+}
@@ -38,6 +38,8 @@ type LokiLoggerConfig struct {
 	BatchWait  time.Duration
 	BatchSize  int
 	Timeout    time.Duration
+	// Concurrency is the number of batches pushed in parallel. Defaults to 1.
+	Concurrency int
 }
 
 // LokiLogger pushes logs to Loki via snappy-compressed protobuf over HTTP.
@@ -47,7 +49,9 @@ type LokiLogger struct {
 	quit    chan struct{}
 	once    sync.Once
 	entries chan lokiEntry
+	batches chan *lokiBatch
 	wg      sync.WaitGroup
+	senders sync.WaitGroup
 }
 
 type lokiEntry struct {
@@ -78,21 +82,37 @@ func NewLokiLogger(cfg LokiLoggerConfig) (*LokiLogger, error) {
 	if cfg.MaxBackoff <= 0 {
 		cfg.MaxBackoff = defaultMaxBackoff
 	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
 
 	l := &LokiLogger{
 		cfg:     cfg,
 		client:  &http.Client{Timeout: cfg.Timeout},
 		quit:    make(chan struct{}),
 		entries: make(chan lokiEntry),
+		batches: make(chan *lokiBatch, cfg.Concurrency),
 	}
 
+	for i := 0; i < cfg.Concurrency; i++ {
+		l.senders.Add(1)
+		go func() {
+			defer l.senders.Done()
+			for batch := range l.batches {
+				l.sendBatch(batch)
+			}
+		}()
+	}
 	l.wg.Add(1)
 	go l.run()
 	return l, nil
 }
 
+// run collects entries of all streams into a single batch, so pushes stay
+// few and large however many streams there are, and hands full or old
+// batches to the senders.
 func (l *LokiLogger) run() {
-	batches := map[string]*lokiBatch{}
+	var batch *lokiBatch
 
 	minWaitCheckFrequency := 10 * time.Millisecond
 	maxWaitCheckFrequency := l.cfg.BatchWait / 10
@@ -103,9 +123,11 @@ func (l *LokiLogger) run() {
 	defer maxWaitCheck.Stop()
 
 	defer func() {
-		for _, batch := range batches {
-			l.sendBatch(batch)
+		if batch != nil {
+			l.batches <- batch
 		}
+		close(l.batches)
+		l.senders.Wait()
 		l.wg.Done()
 	}()
 
@@ -115,26 +137,21 @@ func (l *LokiLogger) run() {
 			return
 
 		case e := <-l.entries:
-			labels := e.labels.String()
-			batch, ok := batches[labels]
-			if !ok {
-				batches[labels] = newLokiBatch(e)
+			if batch == nil {
+				batch = newLokiBatch(e)
 				break
 			}
 			if batch.sizeBytesAfter(e) > l.cfg.BatchSize {
-				l.sendBatch(batch)
-				batches[labels] = newLokiBatch(e)
+				l.batches <- batch
+				batch = newLokiBatch(e)
 				break
 			}
 			batch.add(e)
 
 		case <-maxWaitCheck.C:
-			for labels, batch := range batches {
-				if batch.age() < l.cfg.BatchWait {
-					continue
-				}
-				l.sendBatch(batch)
-				delete(batches, labels)
+			if batch != nil && batch.age() >= l.cfg.BatchWait {
+				l.batches <- batch
+				batch = nil
 			}
 		}
 	}
@@ -159,7 +176,7 @@ func newLokiBatch(entries ...lokiEntry) *lokiBatch {
 }
 
 func (b *lokiBatch) add(e lokiEntry) {
-	b.bytes += len(e.entry.Line)
+	b.bytes += entrySize(e)
 	labels := e.labels.String()
 	if stream, ok := b.streams[labels]; ok {
 		stream.Entries = append(stream.Entries, e.entry)
@@ -172,7 +189,17 @@ func (b *lokiBatch) add(e lokiEntry) {
 }
 
 func (b *lokiBatch) sizeBytesAfter(e lokiEntry) int {
-	return b.bytes + len(e.entry.Line)
+	return b.bytes + entrySize(e)
+}
+
+// entrySize approximates the bytes e adds to a push request: its line and
+// structured metadata. High-cardinality metadata can outweigh the line.
+func entrySize(e lokiEntry) int {
+	size := len(e.entry.Line)
+	for _, md := range e.entry.StructuredMetadata {
+		size += len(md.Name) + len(md.Value)
+	}
+	return size
 }
 
 func (b *lokiBatch) age() time.Duration {
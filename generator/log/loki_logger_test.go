@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -162,3 +163,37 @@ func TestNewLokiLoggerRequiresURL(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "URL is required")
 }
+
+func TestLokiLoggerBatchesManyStreams(t *testing.T) {
+	var mu sync.Mutex
+	var pushes, entries int
+	streams := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := decodePushRequest(t, body)
+		mu.Lock()
+		pushes++
+		for _, s := range req.Streams {
+			streams[s.Labels] = true
+			entries += len(s.Entries)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger, err := NewLokiLogger(LokiLoggerConfig{URL: server.URL, BatchWait: time.Minute, Concurrency: 4})
+	require.NoError(t, err)
+	for i := 0; i < 5000; i++ {
+		labels := model.LabelSet{"app": "demo", "stream": model.LabelValue(strconv.Itoa(i))}
+		require.NoError(t, logger.Handle(labels, time.Now(), "line"))
+	}
+	logger.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 5000, entries)
+	assert.Len(t, streams, 5000)
+	assert.Equal(t, 1, pushes, "entries of different streams share a batch")
+}
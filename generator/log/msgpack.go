@@ -0,0 +1,216 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// The msgpack encoding of the few types the Fluent Forward protocol needs,
+// see https://github.com/msgpack/msgpack/blob/master/spec.md.
+
+// appendMsgpackString appends s as a msgpack str.
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackBin appends p as a msgpack bin.
+func appendMsgpackBin(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, p...)
+}
+
+// appendMsgpackUint appends n as a msgpack int.
+func appendMsgpackUint(b []byte, n uint64) []byte {
+	switch {
+	case n < 128:
+		return append(b, byte(n))
+	case n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+	}
+}
+
+// appendMsgpackArray appends the header of an array of n elements.
+func appendMsgpackArray(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+// appendMsgpackMap appends the header of a map of n key value pairs.
+func appendMsgpackMap(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// appendEventTime appends t as a Fluent EventTime: the ext type 0 of the
+// seconds and nanoseconds since the epoch.
+func appendEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// decodeMsgpack reads a msgpack value: nil, bool, int64, uint64, float64,
+// string, []byte, []any, map[string]any or, for EventTime, time.Time.
+func decodeMsgpack(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c < 0x80:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return decodeMsgpackString(r, int(c&0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackUint(r, 1<<(c-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return b, err
+	case 0xca:
+		n, err := readMsgpackUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return readMsgpackUint(r, 1<<(c-0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := readMsgpackUint(r, size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xd7:
+		ext := make([]byte, 9)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return nil, err
+		}
+		if ext[0] != 0 {
+			return nil, fmt.Errorf("msgpack: unsupported ext type %d", ext[0])
+		}
+		return time.Unix(int64(binary.BigEndian.Uint32(ext[1:5])), int64(binary.BigEndian.Uint32(ext[5:]))).UTC(), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackUint(r, 1<<(c-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackString(r, int(n))
+	case 0xdc, 0xdd:
+		n, err := readMsgpackUint(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readMsgpackUint(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackMap(r, int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", c)
+}
+
+// readMsgpackUint reads a big endian unsigned integer of size bytes.
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func decodeMsgpackString(r *bufio.Reader, n int) (string, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+func decodeMsgpackArray(r *bufio.Reader, n int) ([]any, error) {
+	a := make([]any, n)
+	for i := range a {
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func decodeMsgpackMap(r *bufio.Reader, n int) (map[string]any, error) {
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key %v is not a string", k)
+		}
+		if m[key], err = decodeMsgpack(r); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeAll(t *testing.T, b []byte) any {
+	t.Helper()
+	r := bufio.NewReader(bytes.NewReader(b))
+	v, err := decodeMsgpack(r)
+	require.NoError(t, err)
+	_, err = r.ReadByte()
+	require.Error(t, err, "the value is read whole")
+	return v
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 31, 32, 255, 256, 65535, 65536} {
+		s := strings.Repeat("a", n)
+		assert.Equal(t, s, decodeAll(t, appendMsgpackString(nil, s)), "str of %d", n)
+		assert.Equal(t, []byte(s), decodeAll(t, appendMsgpackBin(nil, []byte(s))), "bin of %d", n)
+	}
+	for _, n := range []uint64{0, 127, 128, 255, 256, 65535, 65536, 1<<32 - 1, 1 << 32} {
+		v := decodeAll(t, appendMsgpackUint(nil, n))
+		if n < 128 {
+			assert.Equal(t, int64(n), v)
+		} else {
+			assert.Equal(t, n, v)
+		}
+	}
+	for _, n := range []int{0, 15, 16, 65536} {
+		b := appendMsgpackArray(nil, n)
+		m := appendMsgpackMap(nil, n)
+		for i := 0; i < n; i++ {
+			b = append(b, 0xc0)
+			m = appendMsgpackString(m, strconv.Itoa(i))
+			m = append(m, 0xc3)
+		}
+		assert.Len(t, decodeAll(t, b), n, "array of %d", n)
+		assert.Len(t, decodeAll(t, m), n, "map of %d", n)
+	}
+
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 123456789, time.UTC)
+	assert.True(t, ts.Equal(decodeAll(t, appendEventTime(nil, ts)).(time.Time)))
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	for _, tc := range []struct {
+		in   []byte
+		want any
+	}{
+		{[]byte{0xc0}, nil},
+		{[]byte{0xc2}, false},
+		{[]byte{0xc3}, true},
+		{[]byte{0xff}, int64(-1)},
+		{[]byte{0xd0, 0x80}, int64(-128)},
+		{[]byte{0xd1, 0xff, 0x00}, int64(-256)},
+		{[]byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{[]byte{0x92, 0x01, 0xa1, 'x'}, []any{int64(1), "x"}},
+		{[]byte{0x81, 0xa3, 'a', 'c', 'k', 0xa2, 'i', 'd'}, map[string]any{"ack": "id"}},
+	} {
+		assert.Equal(t, tc.want, decodeAll(t, tc.in), "%x", tc.in)
+	}
+
+	for _, in := range [][]byte{{}, {0xc1}, {0xa2, 'x'}, {0x81, 0x01, 0x01}, {0xd7, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		_, err := decodeMsgpack(bufio.NewReader(bytes.NewReader(in)))
+		assert.Error(t, err, "%x", in)
+	}
+}
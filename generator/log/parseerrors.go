@@ -0,0 +1,72 @@
+package log
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// JSONParseErrors are the lines of the JSON parser error service: valid JSON
+// mixed with lines that make Loki's json parser set __error__, and fields
+// that collide with stream labels and get an _extracted suffix.
+var JSONParseErrors = []EdgeCase{
+	{Name: "valid", Description: "valid JSON object", Weight: 10, Line: func() string {
+		return fmt.Sprintf(`{"level":"info","msg":"request served","status":200,"duration_ms":%d,"user":"%s"}`, rand.Intn(1000), RandUserID())
+	}},
+	{Name: "truncated", Description: "JSON object cut off mid-value", Weight: 3, Line: func() string {
+		line := fmt.Sprintf(`{"level":"error","msg":"upstream failed","err":"%s","retry":true}`, RandError())
+		return line[:10+rand.Intn(len(line)-11)]
+	}},
+	{Name: "top_level_array", Description: "JSON array instead of an object", Weight: 2, Line: func() string {
+		return fmt.Sprintf(`[{"level":"info","msg":"batch item","id":%d},{"level":"info","msg":"batch item","id":%d}]`, rand.Intn(1000), rand.Intn(1000))
+	}},
+	{Name: "duplicate_keys", Description: "the same key several times with different values", Weight: 2, Line: func() string {
+		return `{"level":"info","status":200,"msg":"first","status":500,"msg":"second","level":"error"}`
+	}},
+	{Name: "deep_nesting", Description: "objects nested past parser depth limits", Weight: 1, Line: func() string {
+		return nestedJSON(200)
+	}},
+	{Name: "number_overflow", Description: "numbers beyond float64 range and precision", Weight: 2, Line: func() string {
+		return `{"level":"warn","msg":"counter overflow","bytes":1e400,"negative":-1e999,"precise":123456789012345678901234567890,"tiny":1e-400}`
+	}},
+	{Name: "label_collision", Description: "keys named like stream labels and structured metadata", Weight: 3, Line: func() string {
+		return `{"level":"fatal","namespace":"whoopsie","service_name":"not-this-one","cluster":"mars-1","env":"nowhere","pod":"ghost-pod","msg":"colliding keys"}`
+	}},
+	{Name: "not_json", Description: "plain text line in a JSON stream", Weight: 1, Line: func() string {
+		return "Exception in thread \"main\" java.lang.IllegalStateException: " + RandError()
+	}},
+}
+
+// LogfmtParseErrors are the lines of the logfmt parser error service.
+var LogfmtParseErrors = []EdgeCase{
+	{Name: "valid", Description: "valid logfmt line", Weight: 10, Line: func() string {
+		return fmt.Sprintf(`level=info msg="request served" status=200 duration=%s user=%s`, RandDuration(), RandUserID())
+	}},
+	{Name: "unbalanced_quote", Description: "quoted value that is never closed", Weight: 3, Line: func() string {
+		return fmt.Sprintf(`level=error msg="upstream failed err=%s retry=true`, strings.ReplaceAll(RandError(), `"`, ""))
+	}},
+	{Name: "empty_key", Description: "values without a key and stray equal signs", Weight: 2, Line: func() string {
+		return `level=warn =orphan msg="empty key" == status= =`
+	}},
+	{Name: "bad_escape", Description: "invalid escape sequences in quoted values", Weight: 2, Line: func() string {
+		return `level=info msg="path C:\Grafana\logs\new" note="tab\q escape"`
+	}},
+	{Name: "label_collision", Description: "keys named like stream labels and structured metadata", Weight: 3, Line: func() string {
+		return `level=fatal namespace=whoopsie service_name=not-this-one cluster=mars-1 env=nowhere pod=ghost-pod traceID=none msg="colliding keys"`
+	}},
+	{Name: "json_in_logfmt", Description: "JSON line in a logfmt stream", Weight: 1, Line: func() string {
+		return `{"level":"info","msg":"wrong format"}`
+	}},
+}
+
+// nestedJSON returns an object nested depth levels deep.
+func nestedJSON(depth int) string {
+	var b strings.Builder
+	b.WriteString(`{"level":"info","msg":"deep","nested":`)
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&b, `{"level%d":`, i)
+	}
+	b.WriteString(`"bottom"`)
+	b.WriteString(strings.Repeat("}", depth+1))
+	return b.String()
+}
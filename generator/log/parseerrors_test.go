@@ -0,0 +1,34 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONParseErrors(t *testing.T) {
+	for _, c := range JSONParseErrors {
+		line := c.Line()
+		var obj map[string]any
+		err := json.Unmarshal([]byte(line), &obj)
+		switch c.Name {
+		case "valid", "duplicate_keys", "label_collision", "deep_nesting":
+			assert.NoError(t, err, "%s: %s", c.Name, line)
+		default:
+			assert.Error(t, err, "%s should not decode into an object: %s", c.Name, line)
+		}
+	}
+	assert.Equal(t, 201, strings.Count(nestedJSON(200), "{"))
+}
+
+func TestLogfmtParseErrors(t *testing.T) {
+	for _, c := range LogfmtParseErrors {
+		line := c.Line()
+		assert.NotEmpty(t, line)
+		if c.Name == "unbalanced_quote" {
+			assert.Equal(t, 1, strings.Count(line, `"`)%2, line)
+		}
+	}
+}
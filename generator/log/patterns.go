@@ -0,0 +1,206 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// PatternSlot is the placeholder Loki's pattern detection uses for the
+// variable parts of a line.
+const PatternSlot = "<_>"
+
+// KnownPattern is a line template with known variable slots: every
+// PatternSlot is replaced by a random number, so the pattern Loki detects
+// for the generated lines is the template itself.
+type KnownPattern struct {
+	Pattern string
+	Level   model.LabelValue
+	// Weight is the relative frequency of the pattern among the patterns
+	// active at the same time.
+	Weight int
+	// From and Until bound when the pattern is emitted, relative to the
+	// start of the data. A zero Until means the pattern is never retired.
+	From  time.Duration
+	Until time.Duration
+}
+
+// knownPatternJSON is the JSON form of a KnownPattern, with durations such as "15m".
+type knownPatternJSON struct {
+	Pattern string `json:"pattern"`
+	Level   string `json:"level,omitempty"`
+	Weight  int    `json:"weight"`
+	From    string `json:"from,omitempty"`
+	Until   string `json:"until,omitempty"`
+}
+
+// UnmarshalJSON reads a pattern written as
+// {"pattern": "msg=\"cache hit\" key=<_>", "level": "info", "weight": 10, "from": "15m", "until": "30m"}.
+func (p *KnownPattern) UnmarshalJSON(data []byte) error {
+	var v knownPatternJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = KnownPattern{Pattern: v.Pattern, Level: model.LabelValue(v.Level), Weight: v.Weight}
+	var err error
+	if v.From != "" {
+		if p.From, err = time.ParseDuration(v.From); err != nil {
+			return fmt.Errorf("pattern %q: from: %w", v.Pattern, err)
+		}
+	}
+	if v.Until != "" {
+		if p.Until, err = time.ParseDuration(v.Until); err != nil {
+			return fmt.Errorf("pattern %q: until: %w", v.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes p in the form read by UnmarshalJSON.
+func (p KnownPattern) MarshalJSON() ([]byte, error) {
+	v := knownPatternJSON{Pattern: p.Pattern, Level: string(p.Level), Weight: p.Weight}
+	if p.From != 0 {
+		v.From = p.From.String()
+	}
+	if p.Until != 0 {
+		v.Until = p.Until.String()
+	}
+	// Keep <_> readable instead of escaping it as HTML.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func (p KnownPattern) activeAt(offset time.Duration) bool {
+	return offset >= p.From && (p.Until == 0 || offset < p.Until)
+}
+
+// PatternGenerator emits lines from a set of KnownPatterns in known
+// proportions and counts how many lines each pattern produced, so the
+// Patterns tab can be checked against a manifest. It is safe for concurrent use.
+type PatternGenerator struct {
+	mu       sync.Mutex
+	patterns []KnownPattern
+	counts   []int
+	start    time.Time
+	end      time.Time
+}
+
+// NewPatternGenerator creates a generator for patterns. Pattern offsets are
+// relative to the static start in static mode, and to the first line otherwise.
+func NewPatternGenerator(patterns []KnownPattern) *PatternGenerator {
+	g := &PatternGenerator{patterns: patterns, counts: make([]int, len(patterns))}
+	if cfg := CurrentStatic(); cfg != nil {
+		g.start = cfg.Start
+	}
+	return g
+}
+
+// Line returns a line for t from one of the patterns active at t, picked by
+// weight. ok is false when no pattern is active.
+func (g *PatternGenerator) Line(t time.Time) (level model.LabelValue, line string, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.start.IsZero() || t.Before(g.start) {
+		g.start = t
+	}
+	if t.After(g.end) {
+		g.end = t
+	}
+
+	offset := t.Sub(g.start)
+	total := 0
+	for _, p := range g.patterns {
+		if p.activeAt(offset) {
+			total += max(p.Weight, 0)
+		}
+	}
+	if total == 0 {
+		return "", "", false
+	}
+	r := rand.Intn(total)
+	for i, p := range g.patterns {
+		if !p.activeAt(offset) || p.Weight <= 0 {
+			continue
+		}
+		if r -= p.Weight; r < 0 {
+			g.counts[i]++
+			level = p.Level
+			if level == "" {
+				level = INFO
+			}
+			return level, fillPatternSlots(p.Pattern), true
+		}
+	}
+	return "", "", false
+}
+
+func fillPatternSlots(pattern string) string {
+	parts := strings.Split(pattern, PatternSlot)
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteString(strconv.Itoa(rand.Intn(100000)))
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// PatternManifest lists the patterns a PatternGenerator emitted and how many
+// lines each produced.
+type PatternManifest struct {
+	Start    time.Time              `json:"start"`
+	End      time.Time              `json:"end"`
+	Total    int                    `json:"total"`
+	Patterns []PatternManifestEntry `json:"patterns"`
+}
+
+// PatternManifestEntry is a pattern of a PatternManifest. Slots are the
+// positions of the PatternSlot placeholders among the whitespace-separated
+// tokens of the pattern; Share is Count / Total.
+type PatternManifestEntry struct {
+	Pattern KnownPattern `json:"pattern"`
+	Slots   []int        `json:"slots"`
+	Count   int          `json:"count"`
+	Share   float64      `json:"share"`
+}
+
+// Manifest returns the patterns emitted so far and their counts.
+func (g *PatternGenerator) Manifest() PatternManifest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m := PatternManifest{Start: g.start, End: g.end}
+	for _, c := range g.counts {
+		m.Total += c
+	}
+	for i, p := range g.patterns {
+		entry := PatternManifestEntry{Pattern: p, Slots: patternSlots(p.Pattern), Count: g.counts[i]}
+		if m.Total > 0 {
+			entry.Share = float64(entry.Count) / float64(m.Total)
+		}
+		m.Patterns = append(m.Patterns, entry)
+	}
+	return m
+}
+
+func patternSlots(pattern string) []int {
+	slots := []int{}
+	for i, token := range strings.Fields(pattern) {
+		if strings.Contains(token, PatternSlot) {
+			slots = append(slots, i)
+		}
+	}
+	return slots
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternGeneratorSchedule(t *testing.T) {
+	g := NewPatternGenerator([]KnownPattern{
+		{Pattern: `msg="always" id=<_>`, Weight: 3},
+		{Pattern: `msg="new" id=<_> n=<_>`, Level: WARN, Weight: 1, From: 10 * time.Minute},
+		{Pattern: `msg="retired"`, Weight: 1, Until: 20 * time.Minute},
+	})
+
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	for ts := start; ts.Before(start.Add(30 * time.Minute)); ts = ts.Add(time.Second) {
+		level, line, ok := g.Line(ts)
+		require.True(t, ok)
+		offset := ts.Sub(start)
+		switch {
+		case strings.Contains(line, "new"):
+			assert.GreaterOrEqual(t, offset, 10*time.Minute)
+			assert.Equal(t, WARN, level)
+			assert.Regexp(t, regexp.MustCompile(`^msg="new" id=\d+ n=\d+$`), line)
+		case strings.Contains(line, "retired"):
+			assert.Less(t, offset, 20*time.Minute)
+			assert.Equal(t, INFO, level)
+		}
+	}
+
+	m := g.Manifest()
+	require.Len(t, m.Patterns, 3)
+	assert.Equal(t, 1800, m.Total)
+	assert.Equal(t, start, m.Start)
+	assert.Equal(t, start.Add(30*time.Minute-time.Second), m.End)
+	assert.Equal(t, []int{1}, m.Patterns[0].Slots)
+	assert.Equal(t, []int{1, 2}, m.Patterns[1].Slots)
+	assert.Empty(t, m.Patterns[2].Slots)
+
+	sum := 0
+	for _, p := range m.Patterns {
+		sum += p.Count
+	}
+	assert.Equal(t, m.Total, sum)
+	// "always" has weight 3 out of 4 or 5 during the whole window.
+	assert.InDelta(t, 0.67, m.Patterns[0].Share, 0.06)
+}
+
+func TestKnownPatternJSON(t *testing.T) {
+	var patterns []KnownPattern
+	require.NoError(t, json.Unmarshal([]byte(`[{"pattern":"msg=<_>","level":"warn","weight":2,"from":"15m","until":"1h"}]`), &patterns))
+	require.Len(t, patterns, 1)
+	assert.Equal(t, KnownPattern{Pattern: "msg=<_>", Level: WARN, Weight: 2, From: 15 * time.Minute, Until: time.Hour}, patterns[0])
+
+	data, err := json.Marshal(patterns[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"pattern":"msg=<_>","level":"warn","weight":2,"from":"15m0s","until":"1h0m0s"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"pattern":"x","from":"soon"}`), &patterns[0]))
+}
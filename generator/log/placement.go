@@ -0,0 +1,156 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// Placement is where a field of a line ends up: inline in the line, in
+// structured metadata, as a stream label, or several of these at once.
+type Placement uint8
+
+const (
+	PlaceLine Placement = 1 << iota
+	PlaceMetadata
+	PlaceLabel
+)
+
+var placementNames = []struct {
+	p    Placement
+	name string
+}{
+	{PlaceLine, "line"},
+	{PlaceMetadata, "metadata"},
+	{PlaceLabel, "label"},
+}
+
+// ParsePlacement parses placements joined with '+', e.g. "line+metadata".
+// "none" drops the field.
+func ParsePlacement(s string) (Placement, error) {
+	if s == "none" {
+		return 0, nil
+	}
+	var p Placement
+	for _, part := range strings.Split(s, "+") {
+		found := false
+		for _, n := range placementNames {
+			if n.name == strings.TrimSpace(part) {
+				p |= n.p
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("field placement: unknown placement %q, expected line, metadata, label or none", part)
+		}
+	}
+	return p, nil
+}
+
+func (p Placement) String() string {
+	var parts []string
+	for _, n := range placementNames {
+		if p&n.p != 0 {
+			parts = append(parts, n.name)
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "+")
+}
+
+// Field is a named value of a line.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// FieldPlacement is where the fields of a service go: Default, unless the
+// field is in Fields.
+type FieldPlacement struct {
+	Default Placement
+	Fields  map[string]Placement
+}
+
+// Of returns the placement of the field name.
+func (f FieldPlacement) Of(name string) Placement {
+	if p, ok := f.Fields[name]; ok {
+		return p
+	}
+	return f.Default
+}
+
+// Place splits fields by placement into stream labels, structured metadata
+// and the fields to write in the line, keeping the order of fields.
+func (f FieldPlacement) Place(fields []Field) (labels model.LabelSet, metadata push.LabelsAdapter, inline []Field) {
+	for _, field := range fields {
+		p := f.Of(field.Name)
+		if p&PlaceLabel != 0 {
+			if labels == nil {
+				labels = model.LabelSet{}
+			}
+			labels[model.LabelName(field.Name)] = model.LabelValue(field.Value)
+		}
+		if p&PlaceMetadata != 0 {
+			metadata = append(metadata, push.LabelAdapter{Name: field.Name, Value: field.Value})
+		}
+		if p&PlaceLine != 0 {
+			inline = append(inline, field)
+		}
+	}
+	return labels, metadata, inline
+}
+
+// Names returns the names among fields placed in p.
+func (f FieldPlacement) Names(fields []string, p Placement) []string {
+	var out []string
+	for _, name := range fields {
+		if f.Of(name)&p != 0 {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// Override returns a copy of f with the placements of spec applied, a comma
+// separated list of field=placement pairs where the field "*" sets the
+// default (e.g. "*=metadata,orderId=line+label").
+func (f FieldPlacement) Override(spec string) (FieldPlacement, error) {
+	out := FieldPlacement{Default: f.Default, Fields: map[string]Placement{}}
+	for name, p := range f.Fields {
+		out.Fields[name] = p
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return FieldPlacement{}, fmt.Errorf("field placement: expected field=placement, got %q", pair)
+		}
+		p, err := ParsePlacement(value)
+		if err != nil {
+			return FieldPlacement{}, err
+		}
+		if name == "*" {
+			out.Default = p
+			continue
+		}
+		out.Fields[name] = p
+	}
+	return out, nil
+}
+
+func (f FieldPlacement) String() string {
+	parts := []string{"*=" + f.Default.String()}
+	names := make([]string, 0, len(f.Fields))
+	for name := range f.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+f.Fields[name].String())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlacement(t *testing.T) {
+	p, err := ParsePlacement("label+metadata")
+	require.NoError(t, err)
+	assert.Equal(t, PlaceLabel|PlaceMetadata, p)
+	assert.Equal(t, "metadata+label", p.String())
+
+	p, err = ParsePlacement("none")
+	require.NoError(t, err)
+	assert.Equal(t, Placement(0), p)
+
+	_, err = ParsePlacement("line+header")
+	assert.Error(t, err)
+}
+
+func TestFieldPlacementPlace(t *testing.T) {
+	placement, err := FieldPlacement{Default: PlaceLine}.Override("*=metadata,id=line+label,secret=none")
+	require.NoError(t, err)
+
+	labels, metadata, inline := placement.Place([]Field{
+		{Name: "id", Value: "42"},
+		{Name: "user", Value: "ada"},
+		{Name: "secret", Value: "hunter2"},
+		{Name: "country", Value: "FR"},
+	})
+	assert.Equal(t, model.LabelSet{"id": "42"}, labels)
+	assert.Equal(t, push.LabelsAdapter{{Name: "user", Value: "ada"}, {Name: "country", Value: "FR"}}, metadata)
+	assert.Equal(t, []Field{{Name: "id", Value: "42"}}, inline)
+	assert.Equal(t, "*=metadata,id=line+label,secret=none", placement.String())
+}
+
+func TestFieldPlacementOverrideKeepsOriginal(t *testing.T) {
+	original := FieldPlacement{Default: PlaceLine, Fields: map[string]Placement{"id": PlaceLabel}}
+	_, err := original.Override("id=metadata")
+	require.NoError(t, err)
+	assert.Equal(t, PlaceLabel, original.Of("id"))
+
+	_, err = original.Override("id")
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// PodLogFormat is the format of the container log files of a PodLogger.
+type PodLogFormat string
+
+const (
+	// PodLogCRI is the format of the kubelet with CRI runtimes, one
+	// "<time> <stream> <P|F> <log>" line per chunk of a line.
+	PodLogCRI PodLogFormat = "cri"
+	// PodLogDocker is Docker's json-file format, one
+	// {"log":...,"stream":...,"time":...} object per chunk of a line.
+	PodLogDocker PodLogFormat = "docker"
+)
+
+// DefaultPodLogDir is the directory of container log files on a node.
+const DefaultPodLogDir = "/var/log/pods"
+
+// PodLogLayout is the layout of container log files under the pod log
+// directory, with the pod name from the pod label or else the structured
+// metadata of ForAllClusters, its UID from the topology, and the container
+// label or else the service as container.
+const PodLogLayout = "<namespace>_<pod>_<pod_uid>/<container>/0.log"
+
+// PodLogMaxLineSize is the size of the chunks container runtimes split long
+// lines into: all but the last chunk of a line are partial.
+const PodLogMaxLineSize = 16 * 1024
+
+// PodLogger implements the Logger interface and writes container log files
+// as the kubelet does, for testing collectors' Kubernetes pipelines. Lines
+// are written to stdout, or stderr for the error level. Files rotate as
+// configured for the FileLogger.
+type PodLogger struct {
+	*FileLogger
+}
+
+// NewPodLogger creates a logger that writes container log files of format
+// under cfg.Dir, DefaultPodLogDir if empty. cfg.Layout and cfg.Encode are
+// ignored.
+func NewPodLogger(cfg FileLoggerConfig, format PodLogFormat) (*PodLogger, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultPodLogDir
+	}
+	cfg.Layout = PodLogLayout
+	switch format {
+	case PodLogCRI:
+		cfg.Encode = func(labels model.LabelSet, t time.Time, message string) string {
+			return CRILines(t, podLogStream(labels), message)
+		}
+	case PodLogDocker:
+		cfg.Encode = func(labels model.LabelSet, t time.Time, message string) string {
+			return DockerJSONLines(t, podLogStream(labels), message)
+		}
+	default:
+		return nil, fmt.Errorf("pod logger: unknown format %q, expected cri or docker", format)
+	}
+	l, err := NewFileLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PodLogger{FileLogger: l}, nil
+}
+
+// Handle implements the Logger interface
+func (l *PodLogger) Handle(labels model.LabelSet, timestamp time.Time, message string) error {
+	return l.HandleWithMetadata(labels, timestamp, message, nil)
+}
+
+// HandleWithMetadata implements the Logger interface. Entries without a pod
+// label or pod structured metadata are rejected, since all of them would end
+// up in the same file.
+func (l *PodLogger) HandleWithMetadata(labels model.LabelSet, timestamp time.Time, message string, metadata push.LabelsAdapter) error {
+	name := string(labels["pod"])
+	if name == "" {
+		name = MetadataValue(metadata, "pod")
+	}
+	if name == "" {
+		return fmt.Errorf("pod logger: no pod label or structured metadata for %s", labels)
+	}
+	pod := filePathElement(name)
+	container := labels["container"]
+	if container == "" {
+		container = labels["service_name"]
+	}
+	labels = labels.Merge(model.LabelSet{
+		"pod":       model.LabelValue(pod),
+		"pod_uid":   model.LabelValue(PodUID(string(labels["cluster"]), string(labels["namespace"]), pod)),
+		"container": container,
+	})
+	return l.FileLogger.HandleWithMetadata(labels, timestamp, message, metadata)
+}
+
+// podLogStream returns the output stream of a line of labels.
+func podLogStream(labels model.LabelSet) string {
+	if labels["level"] == ERROR {
+		return "stderr"
+	}
+	return "stdout"
+}
+
+// podLogChunks splits message into its lines, and the lines into chunks of
+// at most PodLogMaxLineSize bytes. partial is true for all but the last
+// chunk of a line.
+func podLogChunks(message string, chunk func(text string, partial bool)) {
+	for _, line := range strings.Split(message, "\n") {
+		for len(line) > PodLogMaxLineSize {
+			chunk(line[:PodLogMaxLineSize], true)
+			line = line[PodLogMaxLineSize:]
+		}
+		chunk(line, false)
+	}
+}
+
+// CRILines returns message as written by the kubelet to a CRI container log
+// file: one "<time> <stream> F <line>" line per line of message, split in
+// "P" partial lines beyond PodLogMaxLineSize.
+func CRILines(t time.Time, stream, message string) string {
+	var b strings.Builder
+	ts := t.UTC().Format(time.RFC3339Nano)
+	podLogChunks(message, func(text string, partial bool) {
+		tag := "F"
+		if partial {
+			tag = "P"
+		}
+		fmt.Fprintf(&b, "%s %s %s %s\n", ts, stream, tag, text)
+	})
+	return b.String()
+}
+
+// dockerJSONLine is a line of a Docker json-file log.
+type dockerJSONLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// DockerJSONLines returns message as written by Docker's json-file log
+// driver: one object per line of message, whose log ends with a newline
+// unless it is a partial chunk of a line beyond PodLogMaxLineSize.
+func DockerJSONLines(t time.Time, stream, message string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	ts := t.UTC().Format(time.RFC3339Nano)
+	podLogChunks(message, func(text string, partial bool) {
+		if !partial {
+			text += "\n"
+		}
+		// Encoding strings cannot fail.
+		_ = enc.Encode(dockerJSONLine{Log: text, Stream: stream, Time: ts})
+	})
+	return b.String()
+}
+
+// PodLogFile is what a container log file path tells about its container,
+// as collectors discover it.
+type PodLogFile struct {
+	Namespace string
+	Pod       string
+	UID       string
+	Container string
+}
+
+// ParsePodLogPath parses the path of a container log file, laid out as
+// PodLogLayout under any directory.
+func ParsePodLogPath(path string) (PodLogFile, error) {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) < 3 || !strings.HasPrefix(parts[len(parts)-1], "0.log") {
+		return PodLogFile{}, fmt.Errorf("%s: not a container log file", path)
+	}
+	pod := strings.Split(parts[len(parts)-3], "_")
+	if len(pod) != 3 {
+		return PodLogFile{}, fmt.Errorf("%s: expected a <namespace>_<pod>_<uid> directory", path)
+	}
+	return PodLogFile{Namespace: pod[0], Pod: pod[1], UID: pod[2], Container: parts[len(parts)-2]}, nil
+}
+
+// Labels returns the labels collectors give the lines of the file.
+func (f PodLogFile) Labels() model.LabelSet {
+	return model.LabelSet{
+		"namespace":    model.LabelValue(f.Namespace),
+		"pod":          model.LabelValue(f.Pod),
+		"container":    model.LabelValue(f.Container),
+		"service_name": model.LabelValue(f.Container),
+	}
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readCRILines reassembles the lines of a CRI container log file.
+func readCRILines(t *testing.T, path string) (lines, streams []string) {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	var partial string
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), " ", 4)
+		require.Len(t, fields, 4, s.Text())
+		_, err := time.Parse(time.RFC3339Nano, fields[0])
+		require.NoError(t, err)
+		partial += fields[3]
+		if fields[2] == "F" {
+			lines, streams = append(lines, partial), append(streams, fields[1])
+			partial = ""
+		} else {
+			require.Equal(t, "P", fields[2])
+			require.Len(t, fields[3], PodLogMaxLineSize)
+		}
+	}
+	require.NoError(t, s.Err())
+	require.Empty(t, partial, "no partial line left")
+	return lines, streams
+}
+
+// readDockerLines reassembles the lines of a Docker json-file log.
+func readDockerLines(t *testing.T, path string) (lines, streams []string) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var partial string
+	for _, raw := range strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n") {
+		var line dockerJSONLine
+		require.NoError(t, json.Unmarshal([]byte(raw), &line), raw)
+		_, err := time.Parse(time.RFC3339Nano, line.Time)
+		require.NoError(t, err)
+		partial += line.Log
+		if strings.HasSuffix(partial, "\n") {
+			lines, streams = append(lines, strings.TrimSuffix(partial, "\n")), append(streams, line.Stream)
+			partial = ""
+		}
+	}
+	require.Empty(t, partial, "no partial line left")
+	return lines, streams
+}
+
+func TestPodLoggerImplementsLoggerInterface(t *testing.T) {
+	var _ Logger = &PodLogger{}
+}
+
+func TestPodLogger(t *testing.T) {
+	long := strings.Repeat("x", 2*PodLogMaxLineSize+10)
+	for _, tc := range []struct {
+		format PodLogFormat
+		read   func(*testing.T, string) ([]string, []string)
+	}{
+		{PodLogCRI, readCRILines},
+		{PodLogDocker, readDockerLines},
+	} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			dir := t.TempDir()
+			l, err := NewPodLogger(FileLoggerConfig{Dir: dir}, tc.format)
+			require.NoError(t, err)
+
+			type written struct {
+				lines, streams []string
+			}
+			expected := map[*Pod]*written{}
+			ForAllPods("mimir-prod", "mimir-ingester", func(pod *Pod) {
+				labels := pod.Labels()
+				for i, level := range []model.LabelValue{INFO, ERROR, INFO} {
+					message := []string{"started", "failed\n\tat main.go:42", long}[i]
+					require.NoError(t, l.HandleWithMetadata(labels.Merge(model.LabelSet{"level": level}), time.Now(), message, pod.Metadata()))
+				}
+				expected[pod] = &written{
+					lines:   []string{"started", "failed", "\tat main.go:42", long},
+					streams: []string{"stdout", "stderr", "stderr", "stdout"},
+				}
+			})
+			require.NoError(t, l.Close())
+			require.NotEmpty(t, expected)
+
+			for pod, w := range expected {
+				path := filepath.Join(dir, "mimir-prod_"+pod.Name+"_"+pod.UID(), "mimir-ingester", "0.log")
+				lines, streams := tc.read(t, path)
+				assert.Equal(t, w.lines, lines)
+				assert.Equal(t, w.streams, streams)
+
+				file, err := ParsePodLogPath(path)
+				require.NoError(t, err)
+				assert.Equal(t, pod.UID(), file.UID)
+				assert.Equal(t, model.LabelSet{
+					"namespace":    pod.Namespace,
+					"pod":          model.LabelValue(pod.Name),
+					"container":    pod.Service,
+					"service_name": pod.Service,
+				}, file.Labels())
+			}
+		})
+	}
+}
+
+func TestPodLoggerPod(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewPodLogger(FileLoggerConfig{Dir: dir}, PodLogCRI)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, l.Close()) })
+
+	labels := model.LabelSet{"namespace": "gateway", "service_name": "nginx"}
+	assert.ErrorContains(t, l.HandleWithMetadata(labels, time.Now(), "line", nil), "no pod")
+
+	pod := DefaultTopology.Pods("gateway", "nginx")[0]
+	require.NoError(t, l.HandleWithMetadata(labels.Merge(model.LabelSet{"pod": model.LabelValue(pod.Name)}), time.Now(), "line", nil))
+	assert.FileExists(t, filepath.Join(dir, "gateway_"+pod.Name+"_"+PodUID("", "gateway", pod.Name), "nginx", "0.log"), "the pod label names the file")
+}
+
+func TestPodLogLines(t *testing.T) {
+	ts := time.Date(2026, 4, 26, 11, 0, 0, 5, time.FixedZone("CEST", 2*3600))
+	assert.Equal(t, "2026-04-26T09:00:00.000000005Z stdout F a\n2026-04-26T09:00:00.000000005Z stdout F b\n", CRILines(ts, "stdout", "a\nb"))
+	// Docker escapes HTML characters as encoding/json does.
+	assert.Equal(t, `{"log":"say \"\u003chi\u003e\"\n","stream":"stderr","time":"2026-04-26T09:00:00.000000005Z"}`+"\n", DockerJSONLines(ts, "stderr", `say "<hi>"`))
+}
+
+func TestPodUID(t *testing.T) {
+	uid := PodUID("prod-eu-west-0", "mimir-prod", "mimir-ingester-abc")
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, uid)
+	assert.Equal(t, uid, PodUID("prod-eu-west-0", "mimir-prod", "mimir-ingester-abc"))
+	assert.NotEqual(t, uid, PodUID("prod-us-east-0", "mimir-prod", "mimir-ingester-abc"))
+}
+
+func TestParsePodLogPath(t *testing.T) {
+	file, err := ParsePodLogPath("/var/log/pods/gateway_nginx-7d9f_0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9/nginx/0.log")
+	require.NoError(t, err)
+	assert.Equal(t, PodLogFile{Namespace: "gateway", Pod: "nginx-7d9f", UID: "0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9", Container: "nginx"}, file)
+
+	for _, path := range []string{"/var/log/pods/gateway_nginx/nginx/0.log", "nginx/0.log", "/var/log/pods/a_b_c/nginx/app.log"} {
+		_, err := ParsePodLogPath(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,284 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Names of the value pools configurable with ConfigurePools.
+const (
+	PoolIPs       = "ips"       // client IPs of the flog formats (flog.FakeIP)
+	PoolResources = "resources" // resource URIs of the flog formats (flog.RandResourceURI)
+	PoolURIs      = "uris"      // API paths (RandURI)
+	PoolOrgs      = "orgs"      // tenant IDs (RandOrgID)
+	PoolUsers     = "users"     // user IDs (RandUserID)
+)
+
+// PoolNames are the names of the configurable value pools.
+var PoolNames = []string{PoolIPs, PoolResources, PoolURIs, PoolOrgs, PoolUsers}
+
+// PoolConfig is the size and skew of a value pool. The zero PoolConfig keeps
+// the default values, picked uniformly.
+type PoolConfig struct {
+	// Size is the number of values of the pool, 0 for the default number.
+	Size int
+	// Zipf is the exponent s of a Zipf law over the order of the values:
+	// the value of rank k is picked with weight 1/k^s. 0 picks uniformly.
+	Zipf float64
+	// Weights override the weight of some values. Weights are relative to
+	// the weight of the first value, 1 with or without Zipf.
+	Weights map[string]float64
+}
+
+// Pool is a list of values picked uniformly, along a Zipf law or by weight,
+// as real traffic where a few clients, endpoints and tenants dominate.
+type Pool struct {
+	values []string
+	// cumulative are the cumulative weights of values, nil to pick uniformly.
+	cumulative []float64
+}
+
+// NewPool returns a pool of values picked as configured by cfg, without
+// resizing them (see PoolValues).
+func NewPool(values []string, cfg PoolConfig) *Pool {
+	p := &Pool{values: values}
+	if cfg.Zipf == 0 && len(cfg.Weights) == 0 {
+		return p
+	}
+	p.cumulative = make([]float64, len(values))
+	total := 0.0
+	for i, v := range values {
+		weight := 1 / math.Pow(float64(i+1), cfg.Zipf)
+		if w, ok := cfg.Weights[v]; ok {
+			weight = w
+		}
+		total += weight
+		p.cumulative[i] = total
+	}
+	if total == 0 {
+		p.cumulative = nil
+	}
+	return p
+}
+
+// PoolValues returns size values: the first of defaults, followed by more(i)
+// for the i-th value when size exceeds them. A size of 0 returns defaults.
+func PoolValues(defaults []string, size int, more func(i int) string) []string {
+	if size <= 0 {
+		return defaults
+	}
+	if size <= len(defaults) {
+		return defaults[:size]
+	}
+	values := append([]string(nil), defaults...)
+	for i := len(defaults); i < size; i++ {
+		values = append(values, more(i))
+	}
+	return values
+}
+
+// Values returns the values of the pool, most frequent first under a Zipf law.
+func (p *Pool) Values() []string {
+	return p.values
+}
+
+// Pick returns a value of the pool drawn from r, or from the math/rand
+// global source when r is nil. Uniform pools draw a single r.Intn.
+func (p *Pool) Pick(r *rand.Rand) string {
+	if p.cumulative == nil {
+		if r == nil {
+			return p.values[rand.Intn(len(p.values))]
+		}
+		return p.values[r.Intn(len(p.values))]
+	}
+	var f float64
+	if r == nil {
+		f = rand.Float64()
+	} else {
+		f = r.Float64()
+	}
+	x := f * p.cumulative[len(p.cumulative)-1]
+	return p.values[sort.Search(len(p.cumulative), func(i int) bool { return p.cumulative[i] > x })]
+}
+
+// Probability returns the probability of picking the i-th value.
+func (p *Pool) Probability(i int) float64 {
+	if p.cumulative == nil {
+		return 1 / float64(len(p.values))
+	}
+	weight := p.cumulative[i]
+	if i > 0 {
+		weight -= p.cumulative[i-1]
+	}
+	return weight / p.cumulative[len(p.cumulative)-1]
+}
+
+var (
+	poolConfigsMu sync.RWMutex
+	poolConfigs   = map[string]PoolConfig{}
+)
+
+// uriPool, orgPool and userPool are the pools of RandURI, RandOrgID and
+// RandUserID, rebuilt by ConfigurePools.
+var uriPool, orgPool, userPool atomic.Pointer[Pool]
+
+// fixedPools are the pools whose values are known in advance, unlike the
+// ips and resources pools drawn randomly by each flog generator: their
+// defaults, how to make more of them and where the pool is stored.
+var fixedPools = map[string]struct {
+	defaults []string
+	more     func(i int) string
+	pool     *atomic.Pointer[Pool]
+}{
+	PoolURIs:  {URI, func(i int) string { return fmt.Sprintf("%s/%d", URI[i%len(URI)], i/len(URI)) }, &uriPool},
+	PoolOrgs:  {OrgIDs, func(i int) string { return strconv.Itoa(3000 + i) }, &orgPool},
+	PoolUsers: {UserIDs, func(i int) string { return strconv.Itoa(100000 + i) }, &userPool},
+}
+
+func init() {
+	buildPools()
+}
+
+func buildPools() {
+	for name, p := range fixedPools {
+		cfg := PoolConfigOf(name)
+		p.pool.Store(NewPool(PoolValues(p.defaults, cfg.Size, p.more), cfg))
+	}
+}
+
+// checkWeights returns an error when cfg weighs a value that is not one of
+// the values of the named pool.
+func checkWeights(name string, cfg PoolConfig) error {
+	p, ok := fixedPools[name]
+	if !ok || len(cfg.Weights) == 0 {
+		return nil
+	}
+	values := map[string]bool{}
+	for _, v := range PoolValues(p.defaults, cfg.Size, p.more) {
+		values[v] = true
+	}
+	var unknown []string
+	for v := range cfg.Weights {
+		if !values[v] {
+			unknown = append(unknown, v)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("value pools: %s: weighted values not in the pool: %s", name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// PoolConfigOf returns the configuration of the named pool.
+func PoolConfigOf(name string) PoolConfig {
+	poolConfigsMu.RLock()
+	defer poolConfigsMu.RUnlock()
+	return poolConfigs[name]
+}
+
+// ConfigurePools sets the configuration of the value pools from spec:
+// 'pool:option,...' entries separated by ';', where an option is 'size=<n>',
+// 'zipf' (exponent 1), 'zipf=<s>', 'uniform', or 'value=weight' to weigh a
+// value of the pool (e.g. 'orgs:zipf=1.2,size=50;uris:/api/loki/v1/push=5').
+// Values of the ips and resources pools are drawn randomly, so they cannot be
+// weighed. Pools not in spec keep their configuration. Pools of flog
+// generators are configured when the generators are created, so configure
+// pools before creating any.
+func ConfigurePools(spec string) error {
+	configs, err := ParsePoolConfigs(spec)
+	if err != nil {
+		return err
+	}
+	for name, cfg := range configs {
+		if err := checkWeights(name, cfg); err != nil {
+			return err
+		}
+	}
+	poolConfigsMu.Lock()
+	for name, cfg := range configs {
+		poolConfigs[name] = cfg
+	}
+	poolConfigsMu.Unlock()
+	buildPools()
+	return nil
+}
+
+// ParsePoolConfigs parses a spec of ConfigurePools.
+func ParsePoolConfigs(spec string) (map[string]PoolConfig, error) {
+	configs := map[string]PoolConfig{}
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, options, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("value pools: expected pool:option,..., got %q", entry)
+		}
+		if !isPoolName(name) {
+			return nil, fmt.Errorf("value pools: unknown pool %q, pools: %s", name, strings.Join(PoolNames, ", "))
+		}
+		cfg, err := parsePoolConfig(options)
+		if err != nil {
+			return nil, fmt.Errorf("value pools: %s: %w", name, err)
+		}
+		if _, fixed := fixedPools[name]; !fixed && len(cfg.Weights) > 0 {
+			return nil, fmt.Errorf("value pools: %s: values are drawn randomly by each generator and cannot be weighed", name)
+		}
+		configs[name] = cfg
+	}
+	return configs, nil
+}
+
+func parsePoolConfig(options string) (PoolConfig, error) {
+	var cfg PoolConfig
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		key, value, hasValue := strings.Cut(option, "=")
+		switch {
+		case option == "uniform":
+			cfg.Zipf = 0
+		case option == "zipf":
+			cfg.Zipf = 1
+		case key == "zipf":
+			s, err := strconv.ParseFloat(value, 64)
+			if err != nil || s <= 0 {
+				return cfg, fmt.Errorf("invalid zipf exponent %q", value)
+			}
+			cfg.Zipf = s
+		case key == "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return cfg, fmt.Errorf("invalid size %q", value)
+			}
+			cfg.Size = size
+		case hasValue && key != "":
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil || weight < 0 {
+				return cfg, fmt.Errorf("invalid weight %q for %s", value, key)
+			}
+			if cfg.Weights == nil {
+				cfg.Weights = map[string]float64{}
+			}
+			cfg.Weights[key] = weight
+		default:
+			return cfg, fmt.Errorf("unknown option %q, expected size=<n>, zipf, zipf=<s>, uniform or value=weight", option)
+		}
+	}
+	return cfg, nil
+}
+
+func isPoolName(name string) bool {
+	for _, n := range PoolNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
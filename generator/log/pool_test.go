@@ -0,0 +1,112 @@
+package log
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolUniformDrawsLikeIntn(t *testing.T) {
+	values := []string{"a", "b", "c", "d", "e"}
+	p := NewPool(values, PoolConfig{})
+	r, expected := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, values[expected.Intn(len(values))], p.Pick(r))
+	}
+}
+
+func TestPoolZipf(t *testing.T) {
+	values := PoolValues(nil, 20, strconv.Itoa)
+	p := NewPool(values, PoolConfig{Zipf: 1.2})
+	r := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	const picks = 200000
+	for i := 0; i < picks; i++ {
+		counts[p.Pick(r)]++
+	}
+	total := 0.0
+	for k := 1; k <= len(values); k++ {
+		total += 1 / math.Pow(float64(k), 1.2)
+	}
+	for i, v := range values {
+		expected := 1 / math.Pow(float64(i+1), 1.2) / total
+		assert.InDelta(t, expected, p.Probability(i), 1e-9, v)
+		assert.InDelta(t, expected, float64(counts[v])/picks, 0.01, v)
+	}
+	assert.Greater(t, counts["0"], 2*counts["2"], "a few values dominate")
+}
+
+func TestPoolWeights(t *testing.T) {
+	p := NewPool([]string{"a", "b", "c"}, PoolConfig{Weights: map[string]float64{"b": 8, "c": 0}})
+	assert.InDelta(t, 1.0/9, p.Probability(0), 1e-9)
+	assert.InDelta(t, 8.0/9, p.Probability(1), 1e-9)
+	assert.Zero(t, p.Probability(2))
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		assert.NotEqual(t, "c", p.Pick(r))
+	}
+
+	p = NewPool([]string{"a", "b"}, PoolConfig{Weights: map[string]float64{"a": 0, "b": 0}})
+	assert.Equal(t, 0.5, p.Probability(0), "without weight, values are picked uniformly")
+}
+
+func TestPoolValues(t *testing.T) {
+	defaults := []string{"a", "b", "c"}
+	assert.Equal(t, defaults, PoolValues(defaults, 0, strconv.Itoa))
+	assert.Equal(t, []string{"a", "b"}, PoolValues(defaults, 2, strconv.Itoa))
+	assert.Equal(t, []string{"a", "b", "c", "3", "4"}, PoolValues(defaults, 5, strconv.Itoa))
+	assert.Equal(t, []string{"a", "b", "c"}, defaults)
+}
+
+func TestParsePoolConfigs(t *testing.T) {
+	configs, err := ParsePoolConfigs(" ips:zipf=1.5,size=200 ; orgs:zipf,1218=5;uris:/api/loki/v1/push=0.5,uniform;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PoolConfig{
+		"ips":  {Size: 200, Zipf: 1.5},
+		"orgs": {Zipf: 1, Weights: map[string]float64{"1218": 5}},
+		"uris": {Weights: map[string]float64{"/api/loki/v1/push": 0.5}},
+	}, configs)
+
+	for _, spec := range []string{
+		"ips",
+		"hosts:zipf",
+		"ips:zipf=0",
+		"ips:zipf=x",
+		"ips:size=0",
+		"ips:size=-1",
+		"ips:zipfian",
+		"ips:a=-1",
+		"ips:=1",
+		"ips:1.2.3.4=2",
+		"resources:/index.html=2",
+	} {
+		_, err := ParsePoolConfigs(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestConfigurePools(t *testing.T) {
+	t.Cleanup(func() {
+		poolConfigs = map[string]PoolConfig{}
+		buildPools()
+	})
+	require.NoError(t, ConfigurePools("orgs:size=2,1218=0;users:size=12,zipf"))
+	assert.Equal(t, []string{"1218", "29"}, orgPool.Load().Values())
+	assert.Equal(t, "29", RandOrgID())
+	assert.Len(t, userPool.Load().Values(), 12)
+	assert.Equal(t, "100011", userPool.Load().Values()[11])
+	assert.Equal(t, URI, uriPool.Load().Values(), "pools not in spec keep their values")
+
+	require.Error(t, ConfigurePools("orgs:size=x"))
+	assert.Equal(t, PoolConfig{Size: 2, Weights: map[string]float64{"1218": 0}}, PoolConfigOf(PoolOrgs), "invalid specs change nothing")
+
+	require.NoError(t, ConfigurePools("users:size=12,100011=3"), "values beyond the defaults can be weighed")
+	err := ConfigurePools("orgs:size=2,3000=1,1218=2,29x=1")
+	require.Error(t, err, "weights of values not in the pool are rejected")
+	assert.Contains(t, err.Error(), "29x, 3000")
+	assert.Equal(t, PoolConfig{Size: 2, Weights: map[string]float64{"1218": 0}}, PoolConfigOf(PoolOrgs), "invalid specs change nothing")
+}
@@ -0,0 +1,220 @@
+package log
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler runs every emitter of the generator from a single loop, ordered
+// by the (virtual) timestamp of each emitter's next line, instead of one
+// sleeping goroutine per line pattern.
+//
+// In live mode due emitters are handed to a pool of workers and rescheduled
+// after their AppLogger's interval. In static mode emitters run inline in
+// timestamp order, so the generated data only depends on the seed: running
+// them in parallel would interleave their draws from the math/rand global
+// source differently on every run. StaticConfig.Throttle is slept once per
+// step of the virtual clock rather than once per line.
+type Scheduler struct {
+	mu    sync.Mutex
+	queue emitterQueue
+	seq   uint64
+	// wake is signalled when an emitter is (re)added, so the run loop can
+	// recompute how long to wait.
+	wake chan struct{}
+}
+
+// emitter is one recurring line pattern of an AppLogger.
+type emitter struct {
+	app  *AppLogger
+	fn   func(t time.Time)
+	next time.Time
+	// seq breaks ties between emitters due at the same time, in the order
+	// they were queued.
+	seq uint64
+
+	// Static mode: the AppLogger's window is shared by all of its
+	// emitters, so emitter i emits iterations i, i+n, i+2n, ... where n,
+	// the stride, is the number of emitters of the AppLogger when the first
+	// of them runs.
+	iter   int64
+	stride int64
+}
+
+// NewScheduler creates an empty Scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{wake: make(chan struct{}, 1)}
+}
+
+var defaultScheduler = NewScheduler()
+
+// RunScheduler runs the scheduler used by AppLoggers that have not been given
+// one with SetScheduler. See Scheduler.Run.
+func RunScheduler(ctx context.Context, workers int) {
+	defaultScheduler.Run(ctx, workers)
+}
+
+// Add registers fn to be called for every line app emits.
+func (s *Scheduler) Add(app *AppLogger, fn func(t time.Time)) {
+	e := &emitter{app: app, fn: fn}
+	if app.static != nil {
+		if app.staticStride.Load() != 0 {
+			// Its iterations are already shared by the other emitters.
+			log.Printf("scheduler: ignoring an emitter of %s scheduled after its first line in static mode", app.labels)
+			return
+		}
+		e.iter = app.staticEmitters.Add(1) - 1
+		if e.iter >= app.staticIters {
+			return
+		}
+		e.next = app.static.Start.Add(time.Duration(e.iter) * app.static.Step)
+	} else {
+		e.next = time.Now()
+	}
+	s.push(e)
+}
+
+func (s *Scheduler) push(e *emitter) {
+	s.mu.Lock()
+	s.seq++
+	e.seq = s.seq
+	heap.Push(&s.queue, e)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run executes emitters until ctx is done. In static mode it also returns
+// once every emitter has covered the static window.
+func (s *Scheduler) Run(ctx context.Context, workers int) {
+	if StaticEnabled() {
+		s.runStatic(ctx)
+		return
+	}
+	s.runLive(ctx, workers)
+}
+
+func (s *Scheduler) runStatic(ctx context.Context) {
+	var now time.Time
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		if s.queue.Len() == 0 {
+			s.mu.Unlock()
+			return
+		}
+		e := heap.Pop(&s.queue).(*emitter)
+		s.mu.Unlock()
+
+		cfg := e.app.static
+		if e.next.After(now) {
+			if !now.IsZero() && cfg.Throttle > 0 {
+				time.Sleep(cfg.Throttle)
+			}
+			now = e.next
+		}
+		if e.stride == 0 {
+			// The first emitter of the AppLogger to run fixes the stride of
+			// all of them.
+			e.app.staticStride.CompareAndSwap(0, e.app.staticEmitters.Load())
+			e.stride = e.app.staticStride.Load()
+		}
+
+		e.fn(e.next)
+
+		e.iter += e.stride
+		if e.iter < e.app.staticIters {
+			e.next = cfg.Start.Add(time.Duration(e.iter) * cfg.Step)
+			s.push(e)
+		}
+	}
+}
+
+func (s *Scheduler) runLive(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	jobs := make(chan *emitter)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				e.fn(e.next)
+				// Rescheduling only after fn returns keeps an emitter from
+				// running concurrently with itself.
+				e.next = time.Now().Add(e.app.interval())
+				s.push(e)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		var e *emitter
+		wait := time.Hour
+		if s.queue.Len() > 0 {
+			if d := time.Until(s.queue[0].next); d > 0 {
+				wait = d
+			} else {
+				e = heap.Pop(&s.queue).(*emitter)
+			}
+		}
+		s.mu.Unlock()
+
+		if e != nil {
+			select {
+			case jobs <- e:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// emitterQueue is a container/heap of emitters ordered by next timestamp.
+type emitterQueue []*emitter
+
+func (q emitterQueue) Len() int { return len(q) }
+
+func (q emitterQueue) Less(i, j int) bool {
+	if q[i].next.Equal(q[j].next) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].next.Before(q[j].next)
+}
+
+func (q emitterQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *emitterQueue) Push(x any) { *q = append(*q, x.(*emitter)) }
+
+func (q *emitterQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,129 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func discardLogger() Logger {
+	return LoggerFunc(func(model.LabelSet, time.Time, string, push.LabelsAdapter) error { return nil })
+}
+
+func TestSchedulerStaticCoversWindowInOrder(t *testing.T) {
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	EnableStatic(StaticConfig{Start: start, End: start.Add(time.Minute), Step: 5 * time.Second, Throttle: time.Nanosecond}, 42)
+	t.Cleanup(func() { staticConfig.Store(nil) })
+
+	s := NewScheduler()
+	var got []time.Time
+	var perEmitter [3]int
+	for _, labels := range []model.LabelSet{{"service_name": "a"}, {"service_name": "b"}} {
+		app := NewAppLogger(labels, discardLogger())
+		app.SetScheduler(s)
+		if labels["service_name"] == "a" {
+			for i := range perEmitter[:2] {
+				app.Schedule(func(ts time.Time) {
+					perEmitter[i]++
+					got = append(got, ts)
+				})
+			}
+			continue
+		}
+		app.Schedule(func(ts time.Time) {
+			perEmitter[2]++
+			got = append(got, ts)
+		})
+	}
+	s.Run(context.Background(), 4)
+
+	// Emitters of one AppLogger share its 12 iterations; b has its own.
+	assert.Equal(t, [3]int{6, 6, 12}, perEmitter)
+	require.Len(t, got, 24)
+	for i := 1; i < len(got); i++ {
+		assert.False(t, got[i].Before(got[i-1]), "timestamps must be emitted in order")
+	}
+	assert.Equal(t, start, got[0])
+	assert.Equal(t, start.Add(55*time.Second), got[len(got)-1])
+}
+
+func TestSchedulerStaticStrideIsFixedWhenRunning(t *testing.T) {
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	EnableStatic(StaticConfig{Start: start, End: start.Add(time.Minute), Step: 5 * time.Second, Throttle: time.Nanosecond}, 42)
+	t.Cleanup(func() { staticConfig.Store(nil) })
+
+	s := NewScheduler()
+	app := NewAppLogger(model.LabelSet{"service_name": "a"}, discardLogger())
+	app.SetScheduler(s)
+	seen := map[time.Time]int{}
+	emit := func(ts time.Time) { seen[ts]++ }
+	app.Schedule(func(ts time.Time) {
+		emit(ts)
+		if ts.Equal(start) {
+			app.Schedule(emit)
+		}
+	})
+	app.Schedule(emit)
+	s.Run(context.Background(), 1)
+
+	require.Len(t, seen, 12, "the window is covered")
+	for ts, n := range seen {
+		assert.Equal(t, 1, n, "%s is emitted once", ts)
+	}
+}
+
+func TestSchedulerLiveRunsEmittersUntilCancelled(t *testing.T) {
+	s := NewScheduler()
+	app := NewAppLogger(model.LabelSet{"service_name": "a"}, discardLogger())
+	app.SetScheduler(s)
+	app.SetInterval(func() time.Duration { return time.Millisecond })
+
+	var overlaps, calls atomic.Int64
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	for i := 0; i < 3; i++ {
+		var running atomic.Bool
+		app.Schedule(func(time.Time) {
+			if !running.CompareAndSwap(false, true) {
+				overlaps.Add(1)
+			}
+			defer running.Store(false)
+			calls.Add(1)
+			mu.Lock()
+			seen[i] = true
+			mu.Unlock()
+			time.Sleep(100 * time.Microsecond)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s.Run(ctx, 8)
+
+	assert.Greater(t, calls.Load(), int64(3), "emitters should be rescheduled")
+	assert.Zero(t, overlaps.Load(), "an emitter must not run concurrently with itself")
+	assert.Len(t, seen, 3)
+}
+
+func TestStreamSeed(t *testing.T) {
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	labels := model.LabelSet{"service_name": "a"}
+	pod := func(name string) push.LabelsAdapter { return push.LabelsAdapter{{Name: "pod", Value: name}} }
+
+	EnableStatic(StaticConfig{Start: start, End: start.Add(time.Minute)}, 42)
+	t.Cleanup(func() { staticConfig.Store(nil) })
+	seed := StreamSeed(labels, pod("a-0"))
+	assert.Equal(t, seed, StreamSeed(labels, pod("a-0")), "static seeds only depend on the seed and the stream")
+	assert.NotEqual(t, seed, StreamSeed(labels, pod("a-1")))
+	assert.NotEqual(t, seed, StreamSeed(model.LabelSet{"service_name": "b"}, pod("a-0")))
+
+	EnableStatic(StaticConfig{Start: start, End: start.Add(time.Minute)}, 43)
+	assert.NotEqual(t, seed, StreamSeed(labels, pod("a-0")))
+}
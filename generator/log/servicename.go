@@ -0,0 +1,30 @@
+package log
+
+import "github.com/prometheus/common/model"
+
+// UnknownServiceName is the service_name Loki gives streams it cannot derive
+// a service name for.
+const UnknownServiceName = "unknown_service"
+
+// DiscoverServiceNameLabels is Loki's default limits_config.discover_service_name:
+// the labels Loki derives service_name from, in order, when a stream is
+// pushed without one.
+var DiscoverServiceNameLabels = []model.LabelName{
+	"service", "app", "application", "app_name", "name", "app_kubernetes_io_name",
+	"container", "container_name", "k8s_container_name", "component", "workload",
+	"job", "k8s_job_name",
+}
+
+// DiscoveredServiceName returns the service_name Loki assigns to a stream
+// pushed with labels, with the default discovery settings.
+func DiscoveredServiceName(labels model.LabelSet) string {
+	if name, ok := labels["service_name"]; ok && name != "" {
+		return string(name)
+	}
+	for _, name := range DiscoverServiceNameLabels {
+		if value := labels[name]; value != "" {
+			return string(value)
+		}
+	}
+	return UnknownServiceName
+}
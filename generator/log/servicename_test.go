@@ -0,0 +1,24 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveredServiceName(t *testing.T) {
+	for _, tc := range []struct {
+		labels   model.LabelSet
+		expected string
+	}{
+		{model.LabelSet{"service_name": "api", "app": "web"}, "api"},
+		{model.LabelSet{"app": "web", "job": "ns/scraper"}, "web"},
+		{model.LabelSet{"container": "proxy", "job": "ns/scraper"}, "proxy"},
+		{model.LabelSet{"job": "ns/scraper"}, "ns/scraper"},
+		{model.LabelSet{"k8s_deployment_name": "billing"}, UnknownServiceName},
+		{model.LabelSet{"cluster": "eu-west-1", "namespace": "prod"}, UnknownServiceName},
+	} {
+		assert.Equal(t, tc.expected, DiscoveredServiceName(tc.labels), tc.labels.String())
+	}
+}
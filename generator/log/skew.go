@@ -0,0 +1,117 @@
+package log
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Loki defaults deciding which timestamps are accepted.
+const (
+	// LokiRejectOldSamplesMaxAge is limits_config.reject_old_samples_max_age:
+	// older entries are rejected.
+	LokiRejectOldSamplesMaxAge = 7 * 24 * time.Hour
+	// LokiCreationGracePeriod is limits_config.creation_grace_period: entries
+	// further in the future are rejected.
+	LokiCreationGracePeriod = 10 * time.Minute
+	// LokiOutOfOrderWindow is how far behind the newest entry of a stream an
+	// entry is still accepted, half of ingester.max_chunk_age.
+	LokiOutOfOrderWindow = time.Hour
+)
+
+// Skew is a way of moving the timestamp of an entry away from the time it is
+// emitted, with a relative Weight among the other skews.
+type Skew struct {
+	Name        string
+	Description string
+	Weight      int
+	// Offset returns how far to move the timestamp of an entry. Unused for
+	// SkewDuplicate.
+	Offset func() time.Duration
+	// WallClock skews are checked by Loki against its own clock, so Offset
+	// applies to the wall clock rather than to the time the entry is
+	// emitted, which differs in static mode.
+	WallClock bool
+}
+
+// Time returns the timestamp of an entry emitted at t with skew s: t moved by
+// Offset, or the wall clock moved by Offset for WallClock skews in static
+// mode.
+func (s Skew) Time(t time.Time) (time.Time, time.Duration) {
+	offset := s.Offset()
+	if s.WallClock && StaticEnabled() {
+		return time.Now().Add(offset), offset
+	}
+	return t.Add(offset), offset
+}
+
+// SkewDuplicate is the name of the skew repeating the previous entry of the
+// stream with the same timestamp and line.
+const SkewDuplicate = "duplicate"
+
+// TimestampSkews are the entry timestamp skews of the timestamp-skew/skewed-entries service.
+var TimestampSkews = []Skew{
+	{Name: "in_order", Description: "entry at the time it is emitted", Weight: 20, Offset: func() time.Duration { return 0 }},
+	{Name: "out_of_order", Description: "entry behind the newest entry of the stream, within Loki's out of order window", Weight: 5, Offset: func() time.Duration {
+		return -time.Second - time.Duration(rand.Int63n(int64(LokiOutOfOrderWindow/2)))
+	}},
+	{Name: "old", Description: "entry older than Loki's reject_old_samples_max_age, rejected", Weight: 1, WallClock: true, Offset: func() time.Duration {
+		return -LokiRejectOldSamplesMaxAge - time.Hour - time.Duration(rand.Int63n(int64(24*time.Hour)))
+	}},
+	{Name: "future", Description: "entry in the future beyond Loki's creation_grace_period, rejected", Weight: 1, WallClock: true, Offset: func() time.Duration {
+		return LokiCreationGracePeriod + time.Minute + time.Duration(rand.Int63n(int64(time.Hour)))
+	}},
+	{Name: SkewDuplicate, Description: "exact repeat of the previous entry's timestamp and line, deduplicated by Loki", Weight: 2},
+}
+
+// LineTimestampDrifts are the differences between the timestamp written in
+// the line and the entry timestamp of the timestamp-skew/line-timestamp-drift service.
+var LineTimestampDrifts = []Skew{
+	{Name: "in_sync", Description: "line timestamp equal to the entry timestamp", Weight: 10, Offset: func() time.Duration { return 0 }},
+	{Name: "clock_skew", Description: "line timestamp up to two minutes off either way, as with an unsynchronized clock", Weight: 5, Offset: func() time.Duration {
+		return time.Duration(rand.Int63n(int64(4*time.Minute))) - 2*time.Minute
+	}},
+	{Name: "delayed", Description: "line written 30s to 10m before the entry timestamp, as when a shipper stamps entries on read", Weight: 5, Offset: func() time.Duration {
+		return -30*time.Second - time.Duration(rand.Int63n(int64(570*time.Second)))
+	}},
+	{Name: "timezone", Description: "local time of another timezone written as UTC", Weight: 3, Offset: func() time.Duration {
+		return []time.Duration{2 * time.Hour, -5 * time.Hour, 9 * time.Hour}[rand.Intn(3)]
+	}},
+}
+
+// ParseSkewWeights returns a copy of skews with weights overridden by spec, a
+// comma separated list of name=weight pairs (e.g. "old=0,duplicate=10").
+func ParseSkewWeights(skews []Skew, spec string) ([]Skew, error) {
+	skews = append([]Skew(nil), skews...)
+	err := parseWeights("timestamp skews", "skew", spec, func(name string, weight int) bool {
+		found := false
+		for i := range skews {
+			if skews[i].Name == name {
+				skews[i].Weight = weight
+				found = true
+			}
+		}
+		return found
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skews, nil
+}
+
+// RandSkew picks one of skews by weight. ok is false when all weights are zero.
+func RandSkew(skews []Skew) (s Skew, ok bool) {
+	total := 0
+	for _, s := range skews {
+		total += s.Weight
+	}
+	if total == 0 {
+		return Skew{}, false
+	}
+	r := rand.Intn(total)
+	for _, s := range skews {
+		if r -= s.Weight; r < 0 {
+			return s, true
+		}
+	}
+	return Skew{}, false
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skewByName(t *testing.T, skews []Skew, name string) Skew {
+	t.Helper()
+	for _, s := range skews {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no skew %q", name)
+	return Skew{}
+}
+
+func TestTimestampSkewOffsets(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		ooo := skewByName(t, TimestampSkews, "out_of_order").Offset()
+		assert.True(t, ooo < 0 && -ooo < LokiOutOfOrderWindow, "out_of_order offset %s", ooo)
+
+		old := skewByName(t, TimestampSkews, "old").Offset()
+		assert.Greater(t, -old, LokiRejectOldSamplesMaxAge, "old offset %s", old)
+
+		future := skewByName(t, TimestampSkews, "future").Offset()
+		assert.Greater(t, future, LokiCreationGracePeriod, "future offset %s", future)
+
+		skew := skewByName(t, LineTimestampDrifts, "clock_skew").Offset()
+		assert.LessOrEqual(t, skew.Abs(), 2*time.Minute)
+
+		delayed := skewByName(t, LineTimestampDrifts, "delayed").Offset()
+		assert.True(t, delayed <= -30*time.Second && delayed >= -10*time.Minute, "delayed offset %s", delayed)
+	}
+	assert.Nil(t, skewByName(t, TimestampSkews, SkewDuplicate).Offset)
+}
+
+func TestSkewTimeInStaticMode(t *testing.T) {
+	start := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	EnableStatic(StaticConfig{Start: start, End: start.Add(time.Minute)}, 42)
+	t.Cleanup(func() { staticConfig.Store(nil) })
+
+	ts, offset := skewByName(t, TimestampSkews, "future").Time(start)
+	assert.WithinDuration(t, time.Now().Add(offset), ts, time.Minute, "future entries are in the future of the wall clock")
+	ts, offset = skewByName(t, TimestampSkews, "old").Time(start)
+	assert.WithinDuration(t, time.Now().Add(offset), ts, time.Minute, "old entries are old for the wall clock")
+	ts, offset = skewByName(t, TimestampSkews, "out_of_order").Time(start)
+	assert.Equal(t, start.Add(offset), ts, "other skews follow the virtual clock")
+}
+
+func TestParseSkewWeights(t *testing.T) {
+	skews, err := ParseSkewWeights(TimestampSkews, "in_order=0,out_of_order=0,old=0,future=0")
+	require.NoError(t, err)
+	assert.Equal(t, 20, TimestampSkews[0].Weight, "defaults are not modified")
+	for i := 0; i < 100; i++ {
+		s, ok := RandSkew(skews)
+		require.True(t, ok)
+		assert.Equal(t, SkewDuplicate, s.Name)
+	}
+
+	_, err = ParseSkewWeights(TimestampSkews, "skewed=1")
+	assert.ErrorContains(t, err, `unknown skew "skewed"`)
+
+	skews, err = ParseSkewWeights(LineTimestampDrifts, "in_sync=0,clock_skew=0,delayed=0,timezone=0")
+	require.NoError(t, err)
+	_, ok := RandSkew(skews)
+	assert.False(t, ok)
+}
@@ -1,13 +1,15 @@
 package log
 
 import (
+	"hash/fnv"
 	"math/rand"
-	"sync"
 	"sync/atomic"
 	"time"
 
 	fake3 "github.com/brianvoe/gofakeit"
 	fake7 "github.com/brianvoe/gofakeit/v7"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
 )
 
 // StaticConfig describes the deterministic time window and step size used by
@@ -17,19 +19,21 @@ type StaticConfig struct {
 	Start time.Time
 	End   time.Time
 	Step  time.Duration
-	// Throttle is a small real-time pause between iterations to avoid
-	// overwhelming the Loki ingester while pushing the snapshot.
+	// Throttle is a small real-time pause between two steps of the virtual
+	// clock to avoid overwhelming the Loki ingester while pushing the
+	// snapshot.
 	Throttle time.Duration
 }
 
 var (
 	staticConfig atomic.Pointer[StaticConfig]
+	staticSeed   atomic.Int64
 )
 
 // EnableStatic switches the generator to deterministic ("static") mode and
 // seeds both gofakeit packages and the math/rand global source. Subsequent
-// calls to NewAppLogger pick up the configuration so each goroutine emits
-// logs with timestamps generated by a virtual clock.
+// calls to NewAppLogger pick up the configuration so the scheduler emits
+// their logs with timestamps generated by a virtual clock.
 func EnableStatic(cfg StaticConfig, seed int64) {
 	if cfg.Step <= 0 {
 		cfg.Step = 5 * time.Second
@@ -38,11 +42,29 @@ func EnableStatic(cfg StaticConfig, seed int64) {
 		cfg.Throttle = 100 * time.Microsecond
 	}
 	staticConfig.Store(&cfg)
+	staticSeed.Store(seed)
 	rand.Seed(seed) //nolint:staticcheck // intentional for determinism
 	fake3.Seed(seed)
 	_ = fake7.Seed(uint64(seed))
 }
 
+// StreamSeed returns a seed for a random source of its own for the stream
+// with labels and metadata (e.g. a flog.Generator). In static mode it is
+// derived from the seed, the labels and the metadata, so each stream gets the
+// same values on every run whatever the other streams draw; in live mode it
+// is random.
+func StreamSeed(labels model.LabelSet, metadata push.LabelsAdapter) int64 {
+	if !StaticEnabled() {
+		return rand.Int63()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(labels.String()))
+	for _, l := range metadata {
+		h.Write([]byte(l.Name + "=" + l.Value + ","))
+	}
+	return staticSeed.Load() ^ int64(h.Sum64())
+}
+
 // CurrentStatic returns the active static configuration, or nil when the
 // generator is running in live mode.
 func CurrentStatic() *StaticConfig {
@@ -54,8 +76,8 @@ func StaticEnabled() bool {
 	return staticConfig.Load() != nil
 }
 
-// staticIters returns the number of iterations a single AppLogger should
-// emit when static mode is active.
+// staticIters returns the number of lines a single AppLogger should emit
+// across all of its emitters when static mode is active.
 func staticIters() int64 {
 	cfg := staticConfig.Load()
 	if cfg == nil {
@@ -67,24 +89,3 @@ func staticIters() int64 {
 	}
 	return int64(span / cfg.Step)
 }
-
-// generatorWG tracks goroutines spawned via Go(). main can wait on it to
-// know when all generator goroutines have exited (used in static mode to
-// drive the program to a graceful shutdown).
-var generatorWG sync.WaitGroup
-
-// Go spawns a goroutine and registers it with a shared WaitGroup. Used by
-// the generator entry points so that main.go can wait for all of them to
-// finish before flushing the Loki client and exiting in static mode.
-func Go(fn func()) {
-	generatorWG.Add(1)
-	go func() {
-		defer generatorWG.Done()
-		fn()
-	}()
-}
-
-// WaitGenerators blocks until all goroutines started via Go have returned.
-func WaitGenerators() {
-	generatorWG.Wait()
-}
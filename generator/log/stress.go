@@ -0,0 +1,167 @@
+package log
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// StressConfig configures the high-cardinality stress mode: a large number of
+// streams in the stress namespace, each with extra labels of configurable
+// cardinality, and lines with many structured metadata keys.
+type StressConfig struct {
+	// Streams is the number of streams alive at any time.
+	Streams int
+	// Labels is the number of stress_label_<n> labels added to each stream.
+	Labels int
+	// LabelCardinality is the number of distinct values of each label.
+	// LabelCardinality^Labels must be at least Streams, or twice as much
+	// with Churn.
+	LabelCardinality int
+	// MetadataKeys is the number of distinct structured metadata keys, of
+	// which MetadataPerLine are attached to each line.
+	MetadataKeys    int
+	MetadataPerLine int
+	// MetadataCardinality is the number of distinct values of each metadata key.
+	MetadataCardinality int
+	// Churn is the lifetime of a stream: every stream is replaced by a new one
+	// with different label values after Churn. Zero disables churn.
+	Churn time.Duration
+	// Rate is the total number of lines per second across all streams.
+	Rate int
+	// Shards is the number of emitters the streams are spread over.
+	Shards int
+}
+
+// Validate checks that c can produce Streams distinct streams and fills in defaults.
+func (c *StressConfig) Validate() error {
+	if c.Streams <= 0 {
+		return fmt.Errorf("stress: streams must be positive, got %d", c.Streams)
+	}
+	if c.Labels <= 0 || c.LabelCardinality <= 0 {
+		return fmt.Errorf("stress: labels and label cardinality must be positive, got %d and %d", c.Labels, c.LabelCardinality)
+	}
+	// With churn, replaced and replacing streams are alive at the same time.
+	needed := float64(c.Streams)
+	if c.Churn > 0 {
+		needed *= 2
+	}
+	if math.Pow(float64(c.LabelCardinality), float64(c.Labels)) < needed {
+		return fmt.Errorf("stress: %d labels with %d values each cannot make %.0f distinct streams", c.Labels, c.LabelCardinality, needed)
+	}
+	if c.MetadataPerLine > c.MetadataKeys {
+		c.MetadataPerLine = c.MetadataKeys
+	}
+	if c.MetadataCardinality <= 0 {
+		c.MetadataCardinality = 1
+	}
+	if c.Rate <= 0 {
+		c.Rate = 1000
+	}
+	if c.Shards <= 0 {
+		c.Shards = 16
+	}
+	return nil
+}
+
+// StressLabels returns the labels of stream i at t. Label values are the
+// digits of the stream's id in base LabelCardinality, so streams alive at
+// the same time never collide; churn gives a stream a new id every Churn.
+func (c StressConfig) StressLabels(i int, t time.Time) model.LabelSet {
+	id := c.streamID(i, t)
+	labels := model.LabelSet{
+		"namespace":    "stress",
+		"service_name": "stress",
+		"cluster":      model.LabelValue(Clusters[i%len(Clusters)]),
+	}
+	for n := 0; n < c.Labels; n++ {
+		labels[model.LabelName("stress_label_"+strconv.Itoa(n))] = model.LabelValue("v" + strconv.FormatUint(id%uint64(c.LabelCardinality), 10))
+		id /= uint64(c.LabelCardinality)
+	}
+	return labels
+}
+
+// StressPod returns the pod of stream i at t, named after the stream's id so
+// that every stream, replaced ones included, has a pod of its own.
+func (c StressConfig) StressPod(i int, t time.Time) string {
+	return "stress-" + strconv.FormatUint(c.streamID(i, t), 36)
+}
+
+// streamID returns the id of stream i at t, which changes every Churn.
+func (c StressConfig) streamID(i int, t time.Time) uint64 {
+	id := uint64(i)
+	if c.Churn > 0 {
+		// Spread the replacement of streams over the churn period.
+		// The product of i and Churn overflows 64 bits with millions of
+		// streams, so it is computed on 128.
+		hi, lo := bits.Mul64(uint64(i), uint64(c.Churn))
+		q, _ := bits.Div64(hi, lo, uint64(c.Streams))
+		phase := time.Duration(q)
+		generation := uint64(t.Add(phase).UnixNano() / int64(c.Churn))
+		id += generation * uint64(c.Streams)
+	}
+	return id
+}
+
+// StressMetadata returns MetadataPerLine structured metadata entries with
+// random keys and values.
+func (c StressConfig) StressMetadata() push.LabelsAdapter {
+	if c.MetadataPerLine <= 0 {
+		return nil
+	}
+	metadata := make(push.LabelsAdapter, 0, c.MetadataPerLine)
+	// Consecutive keys from a random start: distinct, without shuffling
+	// every key of a large key set for each line.
+	first := rand.Intn(c.MetadataKeys)
+	for n := 0; n < c.MetadataPerLine; n++ {
+		metadata = append(metadata, push.LabelAdapter{
+			Name:  "meta_" + strconv.Itoa((first+n)%c.MetadataKeys),
+			Value: "v" + strconv.Itoa(rand.Intn(c.MetadataCardinality)),
+		})
+	}
+	return metadata
+}
+
+// StartStress schedules the stress streams on Shards emitters writing to
+// logger. Every tick, each emitter writes Rate/Shards lines, going round its
+// streams in turn. Lines carry the pod of their stream (see StressPod) in
+// their structured metadata, so file loggers write a file per stream.
+func StartStress(cfg StressConfig, logger Logger) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	shards := min(cfg.Shards, cfg.Streams)
+	perTick := max(cfg.Rate/shards, 1)
+	var seq atomic.Int64
+	for shard := 0; shard < shards; shard++ {
+		app := NewAppLogger(model.LabelSet{"namespace": "stress", "service_name": "stress"}, logger)
+		app.SetInterval(func() time.Duration { return time.Second })
+		next := shard
+		app.Schedule(func(t time.Time) {
+			for n := 0; n < perTick; n++ {
+				labels := cfg.StressLabels(next, t)
+				level := RandLevel()
+				labels["level"] = level
+				line := fmt.Sprintf(`level=%s msg="stress line" stream=%d seq=%d duration=%s`, level, next, seq.Add(1), RandDuration())
+				metadata := append(cfg.StressMetadata(), push.LabelAdapter{Name: "pod", Value: cfg.StressPod(next, t)})
+				if err := logger.HandleWithMetadata(labels, t, line, metadata); err != nil {
+					log.Printf("stress: %v", err)
+					return
+				}
+				next += shards
+				if next >= cfg.Streams {
+					next = shard
+				}
+			}
+		})
+	}
+	return nil
+}
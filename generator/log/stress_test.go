@@ -0,0 +1,118 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStressConfigValidate(t *testing.T) {
+	cfg := StressConfig{Streams: 1000, Labels: 2, LabelCardinality: 10}
+	assert.Error(t, cfg.Validate(), "10^2 values cannot make 1000 streams")
+
+	cfg = StressConfig{Streams: 1000, Labels: 3, LabelCardinality: 10, MetadataKeys: 5, MetadataPerLine: 8}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, 5, cfg.MetadataPerLine)
+	assert.Positive(t, cfg.Rate)
+	assert.Positive(t, cfg.Shards)
+}
+
+func TestStressLabelsAreDistinct(t *testing.T) {
+	cfg := StressConfig{Streams: 1000, Labels: 3, LabelCardinality: 10, Churn: time.Minute}
+	assert.Error(t, cfg.Validate(), "churn needs room for replacement streams")
+	cfg.Labels = 4
+	require.NoError(t, cfg.Validate())
+
+	now := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	seen := map[string]bool{}
+	for i := 0; i < cfg.Streams; i++ {
+		labels := cfg.StressLabels(i, now)
+		assert.Len(t, labels, 3+cfg.Labels)
+		seen[labels.String()] = true
+	}
+	assert.Len(t, seen, cfg.Streams)
+
+	// Streams alive half a churn period later don't collide with each other.
+	later := map[string]bool{}
+	for i := 0; i < cfg.Streams; i++ {
+		later[cfg.StressLabels(i, now.Add(30*time.Second)).String()] = true
+	}
+	assert.Len(t, later, cfg.Streams)
+
+	// Churn replaces streams over time.
+	changed := 0
+	for i := 0; i < cfg.Streams; i++ {
+		if !cfg.StressLabels(i, now).Equal(cfg.StressLabels(i, now.Add(30*time.Second))) {
+			changed++
+		}
+	}
+	assert.InDelta(t, cfg.Streams/2, changed, float64(cfg.Streams)/10)
+}
+
+func TestStressPod(t *testing.T) {
+	cfg := StressConfig{Streams: 1000, Labels: 4, LabelCardinality: 10, Churn: time.Minute}
+	require.NoError(t, cfg.Validate())
+
+	now := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	pods := map[string]bool{}
+	for i := 0; i < cfg.Streams; i++ {
+		pods[cfg.StressPod(i, now)] = true
+	}
+	assert.Len(t, pods, cfg.Streams)
+	assert.NotEqual(t, cfg.StressPod(0, now), cfg.StressPod(0, now.Add(cfg.Churn)), "replaced streams get a new pod")
+}
+
+func TestStressChurnPhaseWithManyStreams(t *testing.T) {
+	cfg := StressConfig{Streams: 1e7, Labels: 8, LabelCardinality: 10, Churn: time.Hour}
+	require.NoError(t, cfg.Validate())
+
+	// Streams are replaced at the end of each churn period, shifted by a
+	// phase of Churn*i/Streams.
+	boundary := time.Date(2026, 4, 26, 11, 0, 0, 0, time.UTC)
+	for i, phase := range map[int]time.Duration{
+		0:               0,
+		cfg.Streams / 2: 30 * time.Minute,
+		cfg.Streams - 1: time.Hour - 360*time.Microsecond,
+	} {
+		at := boundary.Add(-phase)
+		assert.NotEqual(t, cfg.streamID(i, at.Add(-time.Nanosecond)), cfg.streamID(i, at), "stream %d is replaced %s before the hour", i, phase)
+		assert.Equal(t, cfg.streamID(i, at), cfg.streamID(i, at.Add(cfg.Churn-time.Nanosecond)), "stream %d lives for a whole churn period", i)
+	}
+}
+
+func TestStartStressWritesPodLogs(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewPodLogger(FileLoggerConfig{Dir: dir}, PodLogCRI)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, l.Close()) })
+
+	s := NewScheduler()
+	prev := defaultScheduler
+	defaultScheduler = s
+	t.Cleanup(func() { defaultScheduler = prev })
+	require.NoError(t, StartStress(StressConfig{Streams: 4, Labels: 1, LabelCardinality: 4, Rate: 4, Shards: 2}, l))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx, 1)
+
+	files, err := filepath.Glob(filepath.Join(dir, "stress_stress-*", "stress", "0.log"))
+	require.NoError(t, err)
+	assert.Len(t, files, 4, "one file per stream")
+}
+
+func TestStressMetadata(t *testing.T) {
+	cfg := StressConfig{Streams: 1, Labels: 1, LabelCardinality: 1, MetadataKeys: 50, MetadataPerLine: 10, MetadataCardinality: 3}
+	require.NoError(t, cfg.Validate())
+	md := cfg.StressMetadata()
+	require.Len(t, md, 10)
+	names := map[string]bool{}
+	for _, m := range md {
+		names[m.Name] = true
+		assert.Contains(t, []string{"v0", "v1", "v2"}, m.Value)
+	}
+	assert.Len(t, names, 10, "keys are not repeated within a line")
+}
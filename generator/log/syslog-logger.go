@@ -5,6 +5,7 @@ import (
 	"log/syslog"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/grafana/loki/pkg/push"
@@ -61,7 +62,7 @@ func (s *SyslogLogger) HandleWithMetadata(labels model.LabelSet, timestamp time.
 	if len(metadata) > 0 {
 		metadataStr = "[meta@1234"
 		for _, label := range metadata {
-			metadataStr += fmt.Sprintf(` %s="%s"`, label.Name, label.Value)
+			metadataStr += fmt.Sprintf(` %s="%s"`, label.Name, EscapeSDParam(label.Value))
 		}
 		metadataStr += "]"
 	}
@@ -83,6 +84,15 @@ func (s *SyslogLogger) HandleWithMetadata(labels model.LabelSet, timestamp time.
 
 }
 
+// sdParamEscaper escapes the characters RFC5424 requires to be escaped in
+// SD-PARAM values.
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// EscapeSDParam escapes '"', '\\' and ']' in an RFC5424 SD-PARAM value.
+func EscapeSDParam(value string) string {
+	return sdParamEscaper.Replace(value)
+}
+
 // formatRFC5424Message formats a message according to RFC5424 syslog protocol
 // Format: <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
 func formatRFC5424Message(hostname, appName, procID, msgID string, facilityNum, severityNum int, metadata, message string) string {
@@ -70,6 +70,21 @@ func TestSyslogLoggerHandleWithMetadata(t *testing.T) {
 	assert.Contains(t, string(mockConn.LastWrite), `request_id="abcdef"`)
 }
 
+func TestSyslogLoggerEscapesMetadata(t *testing.T) {
+	mockConn := &MockConn{}
+	logger := NewSyslogLogger(mockConn, syslog.LOG_DAEMON)
+
+	metadata := push.LabelsAdapter{{Name: "path", Value: `C:\logs\"a"]`}}
+	err := logger.HandleWithMetadata(model.LabelSet{"level": "info"}, time.Now(), "message", metadata)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(mockConn.LastWrite), `[meta@1234 path="C:\\logs\\\"a\"\]"] message`)
+}
+
+func TestEscapeSDParam(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\]d`, EscapeSDParam(`a"b\c]d`))
+}
+
 func TestSyslogLoggerHandleWriteError(t *testing.T) {
 	expectedErr := errors.New("write error")
 	mockConn := &MockConn{
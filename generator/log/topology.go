@@ -0,0 +1,246 @@
+package log
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// Topology models the simulated infrastructure as env → cluster → namespace
+// → service → pod. Everything is derived from hashes of the names, so a
+// service always runs in the same clusters, a namespace always belongs to the
+// same env, and pods keep their names and nodes for the whole run, whatever
+// the seed.
+type Topology struct {
+	mu   sync.Mutex
+	pods map[string][]*Pod
+	// lifecycles are the lifecycles of services whose pods come and go, see
+	// SetLifecycle.
+	lifecycles map[string]LifecycleConfig
+}
+
+// Pod is one replica of a service in a cluster.
+type Pod struct {
+	Env       model.LabelValue
+	Cluster   string
+	Namespace model.LabelValue
+	Service   model.LabelValue
+	// ReplicaSet is the pod-template hash shared by the pods of a rollout.
+	ReplicaSet string
+	Name       string
+	Node       string
+	// Index is the replica number of the pod within its cluster.
+	Index int
+}
+
+// nodesPerCluster is the number of nodes pods are spread over in each cluster.
+const nodesPerCluster = 6
+
+// podNameAlphabet is the alphabet Kubernetes uses for generated name suffixes.
+const podNameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+// DefaultTopology is the topology used by ForAllClusters.
+var DefaultTopology = NewTopology()
+
+// NewTopology creates an empty Topology. Pods are created on first use.
+func NewTopology() *Topology {
+	return &Topology{pods: map[string][]*Pod{}, lifecycles: map[string]LifecycleConfig{}}
+}
+
+// SetLifecycle sets how the pods of svc in namespace change over time, so
+// PodAt returns the pods of the revision deployed at a given time.
+func (t *Topology) SetLifecycle(namespace, svc model.LabelValue, cfg LifecycleConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lifecycles[string(namespace)+"/"+string(svc)] = cfg
+}
+
+// PodAt returns the replica of pod running at ts: with a lifecycle (see
+// SetLifecycle), the pod of the same replica in the revision deployed at ts,
+// as named by PodLifecycle; otherwise pod itself.
+func (t *Topology) PodAt(pod *Pod, ts time.Time) *Pod {
+	t.mu.Lock()
+	cfg, ok := t.lifecycles[string(pod.Namespace)+"/"+string(pod.Service)]
+	t.mu.Unlock()
+	if !ok {
+		return pod
+	}
+	revision, _ := NewPodLifecycle(pod, cfg).revision(ts)
+	return newPod(pod.Cluster, pod.Namespace, pod.Service, ReplicaSetHash(pod.Cluster, pod.Namespace, pod.Service, revision), pod.Index)
+}
+
+// Pods returns the pods of svc in namespace across all of its clusters.
+func (t *Topology) Pods(namespace, svc model.LabelValue) []*Pod {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := string(namespace) + "/" + string(svc)
+	if pods, ok := t.pods[key]; ok {
+		return pods
+	}
+	var pods []*Pod
+	for _, cluster := range ClustersForService(svc) {
+		rs := ReplicaSetHash(cluster, namespace, svc, 0)
+		for i := 0; i < ReplicasForService(namespace, svc); i++ {
+			pods = append(pods, newPod(cluster, namespace, svc, rs, i))
+		}
+	}
+	t.pods[key] = pods
+	return pods
+}
+
+// Pod returns the pod of svc in namespace with the given name, or nil.
+func (t *Topology) Pod(namespace, svc model.LabelValue, name string) *Pod {
+	for _, pod := range t.Pods(namespace, svc) {
+		if pod.Name == name {
+			return pod
+		}
+	}
+	return nil
+}
+
+func newPod(cluster string, namespace, svc model.LabelValue, rs string, index int) *Pod {
+	name := fmt.Sprintf("%s-%s-%s", svc, rs, hashString(5, cluster, string(namespace), string(svc), rs, strconv.Itoa(index)))
+	if string(svc) == lessRandomPodLabelName {
+		// Hardcode the pod name ID for the tempo-ingester service so we can consistently query metadata in e2e tests.
+		name = lessRandomPodLabelName + "-hc-" + strconv.Itoa(index) + hashString(3, cluster, string(namespace), rs)
+	}
+	return &Pod{
+		Env:        EnvForNamespace(namespace),
+		Cluster:    cluster,
+		Namespace:  namespace,
+		Service:    svc,
+		ReplicaSet: rs,
+		Name:       name,
+		Node:       PodNode(cluster, name),
+		Index:      index,
+	}
+}
+
+// Labels returns the stream labels of the pod.
+func (p *Pod) Labels() model.LabelSet {
+	return model.LabelSet{
+		"env":              p.Env,
+		"cluster":          model.LabelValue(p.Cluster),
+		"__stream_shard__": model.LabelValue(shardForCluster(p.Cluster)),
+		"namespace":        p.Namespace,
+		"service_name":     p.Service,
+		"service":          p.Service, // Match Prometheus span metrics for Metrics Drilldown "Related logs"
+		"file":             "C:\\Grafana\\logs\\" + p.Namespace + ".txt",
+	}
+}
+
+// Metadata returns structured metadata for one entry of the pod, including
+// its node.
+func (p *Pod) Metadata() push.LabelsAdapter {
+	return append(RandStructuredMetadata(p.Name), push.LabelAdapter{Name: "node", Value: p.Node})
+}
+
+// UID returns the Kubernetes UID of the pod.
+func (p *Pod) UID() string {
+	return PodUID(p.Cluster, string(p.Namespace), p.Name)
+}
+
+// PodUID returns the UID of the pod named name in namespace of cluster, a
+// version 4 UUID derived from the names so it is the same for every run.
+func PodUID(cluster, namespace, name string) string {
+	h := fnv.New128a()
+	for _, p := range []string{cluster, namespace, name} {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	b := h.Sum(nil)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ClustersForService returns the clusters svc runs in.
+func ClustersForService(svc model.LabelValue) []string {
+	if UseFullDataForService(svc) {
+		return Clusters
+	}
+	return Clusters[:1]
+}
+
+// ReplicasForService returns how many pods svc runs per cluster.
+func ReplicasForService(namespace, svc model.LabelValue) int {
+	if !UseFullDataForService(svc) {
+		return 1
+	}
+	if string(svc) == lessRandomPodLabelName {
+		return 8
+	}
+	return int(hash(string(namespace), string(svc))%10) + 1
+}
+
+// EnvForNamespace returns the env a namespace belongs to.
+func EnvForNamespace(namespace model.LabelValue) model.LabelValue {
+	for _, env := range namespaces {
+		if env == string(namespace) || strings.HasSuffix(string(namespace), "-"+env) {
+			return model.LabelValue(env)
+		}
+	}
+	return model.LabelValue(namespaces[hash(string(namespace))%uint32(len(namespaces))])
+}
+
+// NodesForCluster returns the names of the nodes of cluster.
+func NodesForCluster(cluster string) []string {
+	nodes := make([]string, nodesPerCluster)
+	for i := range nodes {
+		h := hash(cluster, strconv.Itoa(i))
+		nodes[i] = fmt.Sprintf("ip-10-%d-%d-%d.%s.compute.internal", h%16, (h>>8)%256, (h>>16)%256, cluster)
+	}
+	return nodes
+}
+
+// PodNode returns the node of cluster the pod named name runs on.
+func PodNode(cluster, name string) string {
+	nodes := NodesForCluster(cluster)
+	return nodes[hash(name)%uint32(len(nodes))]
+}
+
+// ReplicaSetHash returns the pod-template hash of the given revision of a
+// service's deployment.
+func ReplicaSetHash(cluster string, namespace, svc model.LabelValue, revision int) string {
+	return hashString(10, cluster, string(namespace), string(svc), strconv.Itoa(revision))
+}
+
+func shardForCluster(cluster string) string {
+	clusterInt := 0
+	for _, char := range cluster {
+		clusterInt += int(char)
+	}
+	return shards[clusterInt%len(shards)]
+}
+
+func hash(parts ...string) uint32 {
+	h := fnv.New32a()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum32()
+}
+
+// hashString returns an n character [podNameAlphabet] string derived from parts.
+func hashString(n int, parts ...string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	v := h.Sum64()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = podNameAlphabet[v%uint64(len(podNameAlphabet))]
+		v /= uint64(len(podNameAlphabet))
+	}
+	return string(b)
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyIsStable(t *testing.T) {
+	a := NewTopology().Pods("tempo-dev", "tempo-distributor")
+	b := NewTopology().Pods("tempo-dev", "tempo-distributor")
+	require.NotEmpty(t, a)
+	require.Equal(t, len(a), len(b))
+	for i := range a {
+		assert.Equal(t, *a[i], *b[i])
+	}
+}
+
+func TestTopologyEnvPerNamespace(t *testing.T) {
+	assert.Equal(t, "dev", string(EnvForNamespace("mimir-dev")))
+	assert.Equal(t, "prod", string(EnvForNamespace("tempo-prod")))
+	assert.Equal(t, EnvForNamespace("gateway"), EnvForNamespace("gateway"))
+
+	for _, pod := range NewTopology().Pods("tempo-prod", "tempo-ingester") {
+		assert.Equal(t, "prod", string(pod.Labels()["env"]))
+	}
+}
+
+func TestTopologyPods(t *testing.T) {
+	topology := NewTopology()
+	pods := topology.Pods("tempo-prod", "tempo-ingester")
+	assert.Len(t, pods, len(Clusters)*8)
+
+	names := map[string]bool{}
+	hardcoded := regexp.MustCompile(`^tempo-ingester-hc-\d.+$`)
+	for _, pod := range pods {
+		assert.Regexp(t, hardcoded, pod.Name)
+		assert.Contains(t, NodesForCluster(pod.Cluster), pod.Node)
+		assert.Equal(t, pod.Name, MetadataValue(pod.Metadata(), "pod"))
+		assert.Equal(t, pod.Node, MetadataValue(pod.Metadata(), "node"), "the node is structured metadata")
+		assert.Equal(t, pod.Node, MetadataValue(RandStreamMetadata(pod.Metadata()), "node"))
+		names[pod.Name] = true
+	}
+	assert.Len(t, names, len(pods), "pod names must be unique")
+
+	// Pods are created once and reused.
+	assert.Same(t, pods[0], topology.Pods("tempo-prod", "tempo-ingester")[0])
+
+	deployment := regexp.MustCompile(`^apache-[bcdfghjklmnpqrstvwxz2456789]{10}-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+	for _, pod := range topology.Pods("gateway", "apache") {
+		assert.Regexp(t, deployment, pod.Name)
+	}
+}
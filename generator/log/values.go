@@ -3,7 +3,6 @@ package log
 import (
 	"math/rand"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -12,19 +11,19 @@ import (
 	"github.com/prometheus/common/model"
 )
 
-// LogSleep sleeps 5–15s between logs to keep CPU usage low.
-func LogSleep() {
-	time.Sleep(time.Duration(5000+rand.Intn(10000)) * time.Millisecond)
+// LogInterval waits 5–15s between logs to keep CPU usage low.
+func LogInterval() time.Duration {
+	return time.Duration(5000+rand.Intn(10000)) * time.Millisecond
 }
 
-// LogSleepFast sleeps 0.5–2s for full-data mode (CI/E2E-critical services).
-func LogSleepFast() {
-	time.Sleep(time.Duration(500+rand.Intn(1500)) * time.Millisecond)
+// LogIntervalFast waits 0.5–2s for full-data mode (CI/E2E-critical services).
+func LogIntervalFast() time.Duration {
+	return time.Duration(500+rand.Intn(1500)) * time.Millisecond
 }
 
-// LogSleepOriginal sleeps 0–5s. Used when GENERATOR_CI_DATA=1 (E2E) to match pre-refactor behavior.
-func LogSleepOriginal() {
-	time.Sleep(time.Duration(rand.Intn(5000)) * time.Millisecond)
+// LogIntervalOriginal waits 0–5s. Used when GENERATOR_CI_DATA=1 (E2E) to match pre-refactor behavior.
+func LogIntervalOriginal() time.Duration {
+	return time.Duration(rand.Intn(5000)) * time.Millisecond
 }
 
 // IsCIData returns true when GENERATOR_CI_DATA=1.
@@ -41,12 +40,17 @@ var e2eCriticalServices = map[string]bool{
 	"mimir-ingester": true, "mimir-distributor": true, "mimir-querier": true, "mimir-ruler": true,
 }
 
+// IsE2ECritical reports whether svc is one of the services E2E tests depend on.
+func IsE2ECritical(svc model.LabelValue) bool {
+	return e2eCriticalServices[string(svc)]
+}
+
 // UseFullDataForService returns true if this service should use full clusters, pods, and fast sleep.
 func UseFullDataForService(svc model.LabelValue) bool {
 	if IsCIData() {
 		return true
 	}
-	return e2eCriticalServices[string(svc)]
+	return IsE2ECritical(svc)
 }
 
 var Clusters = []string{
@@ -136,37 +140,23 @@ func RandLevel() model.LabelValue {
 	}
 }
 
+// RandURI returns a path of the uris pool, see ConfigurePools.
 func RandURI() string {
-	return URI[rand.Intn(len(URI))]
+	return uriPool.Load().Pick(nil)
 }
 
+// ForAllClusters calls cb with the stream labels and structured metadata of
+// every pod of svc in DefaultTopology.
 func ForAllClusters(namespace, svc model.LabelValue, cb func(model.LabelSet, push.LabelsAdapter)) {
-	podCount := 1
-	clusters := Clusters[:1]
-	if UseFullDataForService(svc) {
-		clusters = Clusters
-		podCount = rand.Intn(10) + 1
-		if string(svc) == lessRandomPodLabelName {
-			podCount = 8
-		}
-	}
-	for _, cluster := range clusters {
-		for i := 0; i < podCount; i++ {
-			clusterInt := 0
-			for _, char := range cluster {
-				clusterInt += int(char)
-			}
-
-			cb(model.LabelSet{
-				"env":              model.LabelValue(namespaces[rand.Intn(len(namespaces))]),
-				"cluster":          model.LabelValue(cluster),
-				"__stream_shard__": model.LabelValue(shards[clusterInt%len(shards)]),
-				"namespace":        namespace,
-				"service_name":     svc,
-				"service":          svc, // Match Prometheus span metrics for Metrics Drilldown "Related logs"
-				"file":             "C:\\Grafana\\logs\\" + namespace + ".txt",
-			}, RandStructuredMetadata(string(svc), i))
-		}
+	ForAllPods(namespace, svc, func(pod *Pod) {
+		cb(pod.Labels(), pod.Metadata())
+	})
+}
+
+// ForAllPods calls cb for every pod of svc in DefaultTopology.
+func ForAllPods(namespace, svc model.LabelValue, cb func(*Pod)) {
+	for _, pod := range DefaultTopology.Pods(namespace, svc) {
+		cb(pod)
 	}
 }
 
@@ -179,12 +169,14 @@ func RandSeq(n int) string {
 	return string(b)
 }
 
+// RandOrgID returns a tenant ID of the orgs pool, see ConfigurePools.
 func RandOrgID() string {
-	return OrgIDs[rand.Intn(len(OrgIDs))]
+	return orgPool.Load().Pick(nil)
 }
 
+// RandUserID returns a user ID of the users pool, see ConfigurePools.
 func RandUserID() string {
-	return UserIDs[rand.Intn(len(UserIDs))]
+	return userPool.Load().Pick(nil)
 }
 
 func RandError() string {
@@ -225,19 +217,37 @@ func RandTraceID(prevTrace string) string {
 	return newTrace
 }
 
-func RandStructuredMetadata(svc string, index int) push.LabelsAdapter {
-	podName := svc + "-" + RandSeq(5)
-	if svc == lessRandomPodLabelName {
-		// Hardcode the pod name ID for the tempo-ingester service so we can consistently query metadata in e2e tests.
-		podName = lessRandomPodLabelName + "-hc-" + strconv.Itoa(index) + RandSeq(3)
-	}
+// RandStructuredMetadata returns the structured metadata of one entry of pod,
+// with a random trace and user.
+func RandStructuredMetadata(pod string) push.LabelsAdapter {
 	return push.LabelsAdapter{
 		push.LabelAdapter{Name: "traceID", Value: RandTraceID(defaultTraceId)},
-		push.LabelAdapter{Name: "pod", Value: podName},
+		push.LabelAdapter{Name: "pod", Value: pod},
 		push.LabelAdapter{Name: "user", Value: RandUserID()},
 	}
 }
 
+// RandStreamMetadata returns the structured metadata of one entry of the
+// stream with metadata: like RandStructuredMetadata of its pod, keeping the
+// node of the pod if any.
+func RandStreamMetadata(metadata push.LabelsAdapter) push.LabelsAdapter {
+	md := RandStructuredMetadata(MetadataValue(metadata, "pod"))
+	if node := MetadataValue(metadata, "node"); node != "" {
+		md = append(md, push.LabelAdapter{Name: "node", Value: node})
+	}
+	return md
+}
+
+// MetadataValue returns the value of name in metadata, or "" if it is not set.
+func MetadataValue(metadata push.LabelsAdapter, name string) string {
+	for _, l := range metadata {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
 // MetadataWithTraceID returns a copy of metadata with traceID set. Used when trace ID comes from
 // an emitted span so logs and traces share the same ID for trace-to-logs.
 func MetadataWithTraceID(metadata push.LabelsAdapter, traceID string) push.LabelsAdapter {
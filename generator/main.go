@@ -9,7 +9,6 @@ import (
 	"net"
 	"os"
 	"os/signal"
-	"strings"
 	"time"
 
 	"github.com/grafana/explore-logs/generator/log"
@@ -30,15 +29,140 @@ func main() {
 	syslogProtocol := flag.String("syslog-network", "udp", "Syslog network type: 'udp' or 'tcp'")
 	syslogAddr := flag.String("syslog-addr", "127.0.0.1:514", "Syslog remote address (e.g., '127.0.0.1:514')")
 
+	fileDir := flag.String("file-dir", "", "Write the lines of every stream to its own file under this directory instead of Loki, for collectors tailing files")
+	fileLayout := flag.String("file-layout", log.DefaultFileLayout, "File mode: path of the file of a line relative to -file-dir, where <name> is a stream label or structured metadata value")
+	fileFormat := flag.String("file-format", "plain", "File mode: 'plain' lines, or Kubernetes container log files in 'cri' or 'docker' json-file format, laid out as "+log.PodLogLayout+" under -file-dir (e.g. /var/log/pods), ignoring -file-layout")
+	fileMaxSize := flag.Int64("file-max-size", 0, "File mode: rotate files before they exceed this many bytes (0 disables)")
+	fileRotateInterval := flag.Duration("file-rotate-interval", 0, "File mode: rotate files when line timestamps enter a new interval, e.g. 1h (0 disables)")
+	fileCopyTruncate := flag.Bool("file-copytruncate", false, "File mode: rotate by copying and truncating files in place instead of renaming them")
+	fileCompress := flag.Bool("file-compress", false, "File mode: gzip rotated files")
+	fileMaxBackups := flag.Int("file-max-backups", 5, "File mode: rotated files kept per file (0 keeps all)")
+	fileMaxOpen := flag.Int("file-max-open", log.DefaultMaxOpenFiles, "File mode: files kept open, closing the least recently written ones and reopening them on their next line")
+
+	fluentAddr := flag.String("fluent-addr", "", "Send logs with the Fluent Forward protocol to this Fluent Bit or Fluentd forward input (e.g. localhost:24224) instead of Loki")
+	fluentTag := flag.String("fluent-tag", log.DefaultFluentTag, "Fluent mode: tag of the entries, where <name> is a stream label or structured metadata value")
+	fluentAck := flag.Bool("fluent-ack", false, "Fluent mode: require the receiver to acknowledge every chunk, sending unacknowledged chunks again")
+
+	journal := flag.String("journal", "", "Write entries in systemd journal export format to this file ('-' for stdout) instead of Loki, e.g. to pipe into 'systemd-journal-remote -o out.journal -'")
+
 	staticStart := flag.String("static-start", "", "Enable static (deterministic) mode. RFC3339 timestamp marking the start of the data window (e.g. 2026-04-26T11:00:00Z). When set, the generator emits a fixed amount of data inside [start, start+duration] and exits.")
 	staticDuration := flag.Duration("static-duration", 65*time.Minute, "Static mode: duration of the data window starting at -static-start")
 	staticStep := flag.Duration("static-step", 5*time.Second, "Static mode: virtual time advanced per log iteration")
-	staticThrottle := flag.Duration("static-throttle", 100*time.Microsecond, "Static mode: real-time pause between iterations to avoid overwhelming Loki")
+	staticThrottle := flag.Duration("static-throttle", 100*time.Microsecond, "Static mode: real-time pause between two steps of the virtual clock to avoid overwhelming Loki")
 	staticDrain := flag.Duration("static-drain", 10*time.Second, "Static mode: extra time to wait for in-flight pushes after generators finish")
 	staticSeed := flag.Int64("seed", 42, "Static mode: seed used for math/rand and gofakeit so generated data is reproducible")
 
+	include := flag.String("include", "", "Only start matching services: comma separated 'namespace/service' or 'service' globs (e.g. 'mimir-*/*,nginx'), or a label selector over namespace and service_name (e.g. 'namespace=~\"mimir.*\",service_name!=\"nginx\"')")
+	exclude := flag.String("exclude", "", "Do not start matching services, same syntax as -include")
+
+	list := flag.Bool("list", false, "Print the services the generator emits and exit")
+	listFormat := flag.String("list-format", "table", "Output format of -list: 'table' or 'json'")
+	describe := flag.String("describe", "", "Print the registry entry and sample lines of a service ('service' or 'namespace/service') and exit")
+	describeLines := flag.Int("describe-lines", 5, "Number of sample lines printed by -describe")
+
+	workers := flag.Int("workers", 16, "Number of workers emitting due log lines in live mode")
+
+	templates := flag.String("templates", "", "Path to a JSON file of template-defined services ([{\"namespace\":...,\"service\":...,\"template\":...}]), see flog.Template")
+
+	flows := flag.Bool("flows", false, "Simulate requests flowing through several services, logging every hop with the same trace ID (see flows.go)")
+
+	patterns := flag.String("patterns", "", "Path to a JSON file of patterns emitted by patterns/known-patterns ([{\"pattern\":\"msg=<_>\",\"level\":\"info\",\"weight\":10,\"from\":\"15m\",\"until\":\"30m\"}])")
+	patternsManifest := flag.String("patterns-manifest", "", "Write the expected patterns of patterns/known-patterns and their line counts to this file on exit")
+
+	stressStreams := flag.Int("stress-streams", 0, "Stress mode: number of high-cardinality streams in the stress namespace (0 disables)")
+	stressLabels := flag.Int("stress-labels", 4, "Stress mode: stress_label_<n> labels per stream")
+	stressLabelCardinality := flag.Int("stress-label-cardinality", 100, "Stress mode: distinct values per stress label")
+	stressMetadataKeys := flag.Int("stress-metadata-keys", 20, "Stress mode: distinct structured metadata keys")
+	stressMetadataPerLine := flag.Int("stress-metadata-per-line", 5, "Stress mode: structured metadata keys attached to each line")
+	stressMetadataCardinality := flag.Int("stress-metadata-cardinality", 1000, "Stress mode: distinct values per structured metadata key")
+	stressChurn := flag.Duration("stress-churn", 0, "Stress mode: lifetime of a stream before it is replaced by one with new label values (0 disables churn)")
+	stressRate := flag.Int("stress-rate", 1000, "Stress mode: total lines per second across all stress streams")
+	pushConcurrency := flag.Int("push-concurrency", 1, "Number of batches pushed to Loki in parallel")
+
+	flag.BoolVar(&edgeCaseLines, "edge-case-lines", false, "Emit edge-cases/edge-case-lines, whose adversarial lines are not part of the default data")
+	edgeCaseWeights := flag.String("edge-cases", "", "Weights of the edge-cases/edge-case-lines content kinds as name=weight pairs, enabling it (see -edge-case-lines) (e.g. 'too_long=0,ansi=20'); kinds: long, too_long, multiline, tabs, ansi, invalid_utf8, nul, cjk, rtl, emoji, zero_width, long_token")
+
+	parseErrors := flag.Bool("parse-errors", false, "Emit edge-cases/json-parse-errors and edge-cases/logfmt-parse-errors, whose malformed lines are not part of the default data")
+	jsonErrorWeights := flag.String("json-errors", "", "Weights of the edge-cases/json-parse-errors line kinds as name=weight pairs, enabling it (see -parse-errors); kinds: valid, truncated, top_level_array, duplicate_keys, deep_nesting, number_overflow, label_collision, not_json")
+	logfmtErrorWeights := flag.String("logfmt-errors", "", "Weights of the edge-cases/logfmt-parse-errors line kinds as name=weight pairs, enabling it (see -parse-errors); kinds: valid, unbalanced_quote, empty_key, bad_escape, label_collision, json_in_logfmt")
+
+	serviceNameDiscovery := flag.Bool("service-name-discovery", false, "Emit streams without service_name in the service-discovery namespace, with only an app, k8s_deployment_name, container or job label, an OTel service.name, or none of these (see -list for the service name Loki is expected to derive)")
+
+	timestampSkew := flag.Bool("timestamp-skew", false, "Emit the timestamp-skew services: entries out of order, too old, in the future and duplicated, some of which Loki rejects, and lines whose timestamp differs from the entry timestamp")
+	skewWeights := flag.String("skews", "", "Weights of the timestamp-skew/skewed-entries skews as name=weight pairs; skews: in_order, out_of_order, old, future, duplicate")
+	lineDriftWeights := flag.String("line-drifts", "", "Weights of the timestamp-skew/line-timestamp-drift drifts as name=weight pairs; drifts: in_sync, clock_skew, delayed, timezone")
+
+	fieldPlacement := flag.String("field-placement", "", "Where the fields of the shopping-cart-otel and shopping-cart-structured-otel services go, the only services with configurable field placement, as 'service:field=placement,...' entries separated by ';' where placement is line, metadata, label or none, joined with '+', and the field '*' sets the default (e.g. 'shopping-cart-otel:*=metadata,orderId=line+label'). With -otel, labels of OTel services are sent as log attributes, which Loki stores as structured metadata unless configured otherwise")
+
+	valuePools := flag.String("value-pools", "", "Size and skew of the value pools, as 'pool:option,...' entries separated by ';' where an option is size=<n>, zipf (exponent 1), zipf=<s>, uniform, or value=weight to weigh a single value of the uris, orgs or users pool (e.g. 'ips:zipf=1.2,size=200;orgs:zipf,1218=5'); pools: ips, resources, uris, orgs, users. Values are picked uniformly from the default pools otherwise")
+
 	flag.Parse()
 
+	if *templates != "" {
+		if err := loadTemplateServices(*templates); err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+	}
+	edgeCaseLines = edgeCaseLines || *edgeCaseWeights != ""
+	jsonParseErrorLines = *parseErrors || *jsonErrorWeights != ""
+	logfmtParseErrorLines = *parseErrors || *logfmtErrorWeights != ""
+	for _, weights := range []struct {
+		spec  string
+		cases *[]log.EdgeCase
+	}{
+		{*edgeCaseWeights, &edgeCases},
+		{*jsonErrorWeights, &jsonParseErrors},
+		{*logfmtErrorWeights, &logfmtParseErrors},
+	} {
+		cases, err := log.ParseEdgeCaseWeights(*weights.cases, weights.spec)
+		if err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		*weights.cases = cases
+	}
+	for _, weights := range []struct {
+		spec  string
+		skews *[]log.Skew
+	}{
+		{*skewWeights, &timestampSkews},
+		{*lineDriftWeights, &lineTimestampDrifts},
+	} {
+		skews, err := log.ParseSkewWeights(*weights.skews, weights.spec)
+		if err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		*weights.skews = skews
+	}
+	if err := applyFieldPlacements(*fieldPlacement); err != nil {
+		stdlog.Fatalf("generator: %v", err)
+	}
+	if err := log.ConfigurePools(*valuePools); err != nil {
+		stdlog.Fatalf("generator: %v", err)
+	}
+	if *patterns != "" {
+		if err := loadKnownPatterns(*patterns); err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+	}
+
+	selector, err := newServiceSelector(*include, *exclude)
+	if err != nil {
+		stdlog.Fatalf("generator: %v", err)
+	}
+
+	if *list {
+		if err := listServices(os.Stdout, *listFormat, selector); err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		return
+	}
+	if *describe != "" {
+		if err := describeService(os.Stdout, *describe, *describeLines); err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		return
+	}
+
 	if *staticStart != "" {
 		start, err := time.Parse(time.RFC3339, *staticStart)
 		if err != nil {
@@ -61,12 +185,13 @@ func main() {
 	}
 
 	client, err := log.NewLokiLogger(log.LokiLoggerConfig{
-		URL:        *url,
-		TenantID:   *tenantId,
-		Token:      *token,
-		MaxRetries: 1,
-		MinBackoff: 100 * time.Millisecond,
-		MaxBackoff: 100 * time.Millisecond,
+		URL:         *url,
+		TenantID:    *tenantId,
+		Token:       *token,
+		MaxRetries:  1,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  100 * time.Millisecond,
+		Concurrency: *pushConcurrency,
 	})
 	if err != nil {
 		panic(err)
@@ -78,6 +203,9 @@ func main() {
 		defer func() { _ = traceEmitter.Shutdown(context.Background()) }()
 	}
 
+	// sink is the output without per-line spans; request flows emit their
+	// own spans and write to it directly.
+	var sink log.Logger = client
 	var logger log.Logger = client
 	if traceEmitter != nil && !log.IsCIData() && !log.StaticEnabled() {
 		logger = log.NewTraceAwareLogger(logger, traceEmitter, true) // append for Loki line filter
@@ -90,6 +218,7 @@ func main() {
 			fmt.Println(labels, timestamp, message, metadata)
 			return nil
 		})
+		sink = logger
 	} else if *useSyslog {
 		conn, err := net.Dial(*syslogProtocol, *syslogAddr)
 		if err != nil {
@@ -97,59 +226,150 @@ func main() {
 		}
 		defer conn.Close()
 		logger = log.NewSyslogLogger(conn, syslog.LOG_INFO|syslog.LOG_DAEMON)
+		sink = logger
+	} else if *fileDir != "" {
+		cfg := log.FileLoggerConfig{
+			Dir:          *fileDir,
+			Layout:       *fileLayout,
+			MaxSize:      *fileMaxSize,
+			Interval:     *fileRotateInterval,
+			CopyTruncate: *fileCopyTruncate,
+			Compress:     *fileCompress,
+			MaxBackups:   *fileMaxBackups,
+			MaxOpenFiles: *fileMaxOpen,
+		}
+		var fileLogger interface {
+			log.Logger
+			Close() error
+		}
+		if *fileFormat == "plain" {
+			fileLogger, err = log.NewFileLogger(cfg)
+		} else {
+			fileLogger, err = log.NewPodLogger(cfg, log.PodLogFormat(*fileFormat))
+		}
+		if err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		defer fileLogger.Close()
+		logger = fileLogger
+		sink = logger
+	} else if *fluentAddr != "" {
+		fluentLogger, err := log.NewFluentLogger(log.FluentLoggerConfig{
+			Addr:       *fluentAddr,
+			Tag:        *fluentTag,
+			RequireAck: *fluentAck,
+		})
+		if err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+		defer fluentLogger.Stop()
+		logger = fluentLogger
+		sink = logger
+	} else if *journal != "" {
+		out := os.Stdout
+		if *journal != "-" {
+			out, err = os.Create(*journal)
+			if err != nil {
+				stdlog.Fatalf("generator: %v", err)
+			}
+			defer out.Close()
+		}
+		logger = log.NewJournalLogger(out)
+		sink = logger
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	// Creates and starts all apps.
-	for namespace, apps := range generators {
-		for serviceName, generator := range apps {
-			log.ForAllClusters(
-				namespace,
-				serviceName,
-				func(labels model.LabelSet, metadata push.LabelsAdapter) {
-					// Remove `metadata` from nginx logs
-					if serviceName == "nginx" {
-						metadata = push.LabelsAdapter{}
+	// Files are laid out by pod, so in file mode the streams of services
+	// without structured metadata keep their pod as a label, which files
+	// don't carry.
+	podLabel := *fileDir != ""
+
+	// Creates and starts all apps, in registry order so static mode is reproducible.
+	for _, svc := range registeredServices() {
+		if svc.Standalone || !svc.started() {
+			continue
+		}
+		namespace, serviceName, generator := svc.Namespace, svc.Name, svc.generator
+		if !selector.Matches(namespace, serviceName) {
+			continue
+		}
+		log.ForAllClusters(
+			namespace,
+			serviceName,
+			func(labels model.LabelSet, metadata push.LabelsAdapter) {
+				if podLabel && svc.noMetadata {
+					labels = labels.Merge(model.LabelSet{"pod": model.LabelValue(log.MetadataValue(metadata, "pod"))})
+				}
+				metadata = svc.streamMetadata(metadata)
+				var appLogger *log.AppLogger
+				if isOtelService(serviceName) {
+					if !*useOtel {
+						return
 					}
-					var appLogger *log.AppLogger
-					if strings.Contains(string(serviceName), "-otel") {
-						if !*useOtel {
-							return
-						}
-						var otelLogger log.Logger = log.NewOtelLogger(string(serviceName), labels)
-						if traceEmitter != nil && !log.IsCIData() && !log.StaticEnabled() {
-							otelLogger = log.NewTraceAwareLogger(otelLogger, traceEmitter, false) // no append: trace_id in attributes, avoids breaking ParseJSON
-						}
-						appLogger = log.NewAppLogger(labels, otelLogger)
-					} else {
-						appLogger = log.NewAppLogger(labels, logger)
+					var otelLogger log.Logger = log.NewOtelLogger(string(serviceName), labels)
+					if traceEmitter != nil && !log.IsCIData() && !log.StaticEnabled() {
+						otelLogger = log.NewTraceAwareLogger(otelLogger, traceEmitter, false) // no append: trace_id in attributes, avoids breaking ParseJSON
 					}
-					if log.UseFullDataForService(serviceName) {
-						if log.IsCIData() {
-							appLogger.SetSleep(log.LogSleepOriginal)
-						} else {
-							appLogger.SetSleep(log.LogSleepFast)
-						}
+					appLogger = log.NewAppLogger(labels, otelLogger)
+				} else {
+					appLogger = log.NewAppLogger(labels, logger)
+				}
+				if log.UseFullDataForService(serviceName) {
+					if log.IsCIData() {
+						appLogger.SetInterval(log.LogIntervalOriginal)
+					} else {
+						appLogger.SetInterval(log.LogIntervalFast)
 					}
-					generator(ctx, appLogger, metadata)
-				},
-			)
+				}
+				generator(ctx, appLogger, metadata)
+			},
+		)
+	}
+	if selector.Matches("mimir", "mimir-ingester") {
+		startFailingMimirPod(ctx, logger)
+	}
+	if *serviceNameDiscovery {
+		startServiceNameShapes(ctx, logger, *useOtel, selector)
+	}
+	if *timestampSkew {
+		startTimestampSkew(ctx, sink, selector)
+	}
+	if *flows {
+		startFlows(sink, traceEmitter, *useOtel, podLabel, selector)
+	}
+	if *stressStreams > 0 {
+		err := log.StartStress(log.StressConfig{
+			Streams:             *stressStreams,
+			Labels:              *stressLabels,
+			LabelCardinality:    *stressLabelCardinality,
+			MetadataKeys:        *stressMetadataKeys,
+			MetadataPerLine:     *stressMetadataPerLine,
+			MetadataCardinality: *stressMetadataCardinality,
+			Churn:               *stressChurn,
+			Rate:                *stressRate,
+			Shards:              *workers,
+		}, sink)
+		if err != nil {
+			stdlog.Fatalf("generator: %v", err)
+		}
+	}
+
+	// Runs until interrupted, or in static mode until every emitter has
+	// covered the window.
+	log.RunScheduler(ctx, *workers)
+
+	if *patternsManifest != "" {
+		if err := writePatternManifest(*patternsManifest); err != nil {
+			stdlog.Printf("generator: %v", err)
 		}
 	}
-	startFailingMimirPod(ctx, logger)
 
 	if log.StaticEnabled() {
-		// Wait for every spawned generator goroutine to finish, then give the
-		// Loki client a few seconds to flush in-flight pushes before main
-		// returns and the deferred client.Stop() runs.
-		log.WaitGenerators()
+		// Give the Loki client a few seconds to flush in-flight pushes before
+		// main returns and the deferred client.Stop() runs.
 		stdlog.Printf("generator: static mode generators finished; draining for %s", *staticDrain)
 		time.Sleep(*staticDrain)
-		stop()
-		return
 	}
-
-	<-ctx.Done()
 }
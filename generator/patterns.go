@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+)
+
+// knownPatterns are the patterns emitted by patterns/known-patterns unless
+// -patterns replaces them. Offsets are relative to the start of the data:
+// one pattern appears after 15 minutes and another is retired after 30.
+var knownPatterns = []log.KnownPattern{
+	{Pattern: `level=info msg="request handled" method=GET user=<_> status=200 duration_ms=<_>`, Level: log.INFO, Weight: 40},
+	{Pattern: `level=info msg="cache hit" key=<_>`, Level: log.INFO, Weight: 25},
+	{Pattern: `level=debug msg="job scheduled" job_id=<_> queue=default`, Level: log.DEBUG, Weight: 10},
+	{Pattern: `level=warn msg="slow query" table=orders rows=<_> duration_ms=<_>`, Level: log.WARN, Weight: 10},
+	{Pattern: `level=error msg="connection reset by peer" remote_port=<_>`, Level: log.ERROR, Weight: 5},
+	{Pattern: `level=info msg="feature flag evaluated" flag=new-checkout variant=<_>`, Level: log.INFO, Weight: 10, From: 15 * time.Minute},
+	{Pattern: `level=info msg="legacy endpoint called" endpoint=v0 caller_id=<_>`, Level: log.INFO, Weight: 10, Until: 30 * time.Minute},
+}
+
+var (
+	knownPatternsOnce sync.Once
+	knownPatternGen   *log.PatternGenerator
+)
+
+// knownPatternGenerator returns the generator shared by every pod of
+// patterns/known-patterns, so the manifest counts lines across all of them.
+// It is created on first use, once static mode has been configured.
+func knownPatternGenerator() *log.PatternGenerator {
+	knownPatternsOnce.Do(func() {
+		knownPatternGen = log.NewPatternGenerator(knownPatterns)
+	})
+	return knownPatternGen
+}
+
+func init() {
+	register(ServiceInfo{
+		Namespace:   "patterns",
+		Name:        "known-patterns",
+		Description: "Lines from a known set of patterns in known proportions, one introduced after 15m and one retired after 30m; see -patterns and -patterns-manifest",
+		Format:      "logfmt",
+		Fields:      []string{"level", "msg"},
+		Metadata:    defaultMetadata,
+	}, func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		logger.Schedule(func(t time.Time) {
+			if level, line, ok := knownPatternGenerator().Line(t); ok {
+				logger.LogWithMetadata(level, t, line, metadata)
+			}
+		})
+	})
+}
+
+// loadKnownPatterns replaces the patterns of patterns/known-patterns with
+// the JSON array of log.KnownPattern read from path.
+func loadKnownPatterns(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("patterns: %w", err)
+	}
+	var patterns []log.KnownPattern
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return fmt.Errorf("patterns: parsing %s: %w", path, err)
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("patterns: %s defines no patterns", path)
+	}
+	for i, p := range patterns {
+		switch {
+		case p.Pattern == "":
+			return fmt.Errorf("patterns: %s: entry %d has an empty pattern", path, i)
+		case p.Weight <= 0:
+			return fmt.Errorf("patterns: %s: entry %d (%q) has weight %d, expected a positive weight", path, i, p.Pattern, p.Weight)
+		}
+	}
+	knownPatterns = patterns
+	return nil
+}
+
+// writePatternManifest writes the expected patterns of
+// patterns/known-patterns and how many lines each produced to path.
+func writePatternManifest(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("patterns: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(knownPatternGenerator().Manifest()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("patterns: %w", err)
+	}
+	return f.Close()
+}
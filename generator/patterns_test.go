@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKnownPatternsRejectsInvalidEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.json")
+	for manifest, msg := range map[string]string{
+		`[{"pattern":"msg=ok","weight":1},{"pattern":"","weight":1}]`: "entry 1 has an empty pattern",
+		`[{"pattern":"msg=ok"}]`:             `entry 0 ("msg=ok") has weight 0`,
+		`[{"pattern":"msg=ok","weight":-2}]`: `entry 0 ("msg=ok") has weight -2`,
+	} {
+		assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o644))
+		err := loadKnownPatterns(path)
+		if assert.Error(t, err, manifest) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
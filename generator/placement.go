@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/grafana/explore-logs/generator/flog"
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// fieldPlacements are where the fields of services with configurable field
+// placement go, overridden with -field-placement. The defaults are the
+// historical shapes: shopping-cart-otel writes every field inline and
+// shopping-cart-structured-otel also duplicates them into structured metadata.
+var fieldPlacements = map[model.LabelValue]log.FieldPlacement{
+	"shopping-cart-otel":            {Default: log.PlaceLine},
+	"shopping-cart-structured-otel": {Default: log.PlaceLine | log.PlaceMetadata},
+}
+
+// applyFieldPlacements overrides fieldPlacements with spec, a semicolon
+// separated list of service:field=placement,... entries (e.g.
+// "shopping-cart-otel:*=metadata,orderId=line+label"), and updates the
+// documented fields and metadata of the services to match.
+func applyFieldPlacements(spec string) error {
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		svc, fields, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return fmt.Errorf("field placement: expected service:field=placement,..., got %q", entry)
+		}
+		current, ok := fieldPlacements[model.LabelValue(svc)]
+		if !ok {
+			return fmt.Errorf("field placement: service %q has no configurable field placement, expected shopping-cart-otel or shopping-cart-structured-otel", svc)
+		}
+		placement, err := current.Override(fields)
+		if err != nil {
+			return fmt.Errorf("%s: %w", svc, err)
+		}
+		fieldPlacements[model.LabelValue(svc)] = placement
+	}
+
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for i, info := range services {
+		placement, ok := fieldPlacements[info.Name]
+		if !ok {
+			continue
+		}
+		services[i].Fields = placement.Names(shoppingFields, log.PlaceLine)
+		services[i].Metadata = append(placement.Names(shoppingFields, log.PlaceMetadata), defaultMetadata...)
+		if labels := placement.Names(shoppingFields, log.PlaceLabel); len(labels) > 0 {
+			services[i].Description += fmt.Sprintf("; fields as stream labels: %s", strings.Join(labels, ", "))
+		}
+	}
+	return nil
+}
+
+// shoppingCart logs the orders of newOrder, placing their fields as
+// configured in fieldPlacements for svc.
+func shoppingCart(svc model.LabelValue, newOrder func(time.Time) flog.ShoppingCartOrder) LogGenerator {
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		placement := fieldPlacements[svc]
+		logger.Schedule(func(t time.Time) {
+			level := log.RandLevel()
+			switch level {
+			case log.WARN:
+				logger.LogWithMetadata(level, t, fmt.Sprintf("order %d is not valid", gofakeit.Number(1, 10000)), metadata)
+			case log.ERROR:
+				logger.LogWithMetadata(level, t, fmt.Sprintf("error processing order %d", gofakeit.Number(1, 10000)), metadata)
+			default:
+				labels, fields, inline := placement.Place(newOrder(t).Fields())
+				logger.LogWithLabels(level, t, flog.ShoppingCartLine(inline), labels, append(fields, metadata...))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/flog"
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFieldPlacementsRejectsOtherServices(t *testing.T) {
+	err := applyFieldPlacements("apache:*=metadata")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shopping-cart-otel or shopping-cart-structured-otel")
+}
+
+// Before field placement, the structured shopping cart prepended as many
+// empty entries as it had metadata, from a make with a length followed by
+// appends.
+func TestShoppingCartMetadataHasNoEmptyEntries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var lines int
+	capture := log.LoggerFunc(func(_ model.LabelSet, _ time.Time, _ string, metadata push.LabelsAdapter) error {
+		for _, m := range metadata {
+			assert.NotEmpty(t, m.Name)
+		}
+		if lines++; lines == 50 {
+			cancel()
+		}
+		return nil
+	})
+	scheduler := log.NewScheduler()
+	logger := log.NewAppLogger(model.LabelSet{"service_name": "shopping-cart-structured-otel"}, capture)
+	logger.SetScheduler(scheduler)
+	logger.SetInterval(func() time.Duration { return time.Microsecond })
+	shoppingCart("shopping-cart-structured-otel", flog.NewCorrelatedShoppingCartOrder)(ctx, logger, push.LabelsAdapter{{Name: "pod", Value: "a-0"}})
+
+	ctx, cancelTimeout := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelTimeout()
+	scheduler.Run(ctx, 1)
+	assert.GreaterOrEqual(t, lines, 50)
+}
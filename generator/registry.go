@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// ServiceInfo describes a service emitted by the generator. It is what -list
+// and -describe print, so the dataset can be discovered without reading the
+// generator sources.
+type ServiceInfo struct {
+	Namespace   model.LabelValue `json:"namespace"`
+	Name        model.LabelValue `json:"service"`
+	Description string           `json:"description"`
+	// Format is the shape of the log line, e.g. "logfmt", "json" or a flog format name.
+	Format string `json:"format"`
+	// Fields are the fields that can be extracted from the line.
+	Fields []string `json:"fields,omitempty"`
+	// Metadata are the structured metadata keys attached to each entry.
+	Metadata []string `json:"metadata,omitempty"`
+	// Distributions document the numeric fields drawn from known
+	// distributions, with their expected quantiles.
+	Distributions []log.FieldDistribution `json:"distributions,omitempty"`
+	// ExpectedServiceName is the service_name Loki is expected to derive for
+	// services pushed without a service_name label.
+	ExpectedServiceName string `json:"expectedServiceName,omitempty"`
+	// OTel is true for services shipped through the OTel collector (see -otel).
+	OTel        bool `json:"otel"`
+	E2ECritical bool `json:"e2eCritical"`
+	// Standalone services are started once with fixed labels instead of
+	// through log.ForAllClusters.
+	Standalone bool `json:"standalone,omitempty"`
+
+	generator LogGenerator
+	stream    func() (model.LabelSet, push.LabelsAdapter)
+	// noMetadata services drop the structured metadata of their streams.
+	noMetadata bool
+	// optIn, when set, is the flag that must be true for the service to be
+	// started. Opt-in services are not part of the default data.
+	optIn *bool
+}
+
+// started reports whether s is started by a run, regardless of -include and
+// -exclude.
+func (s ServiceInfo) started() bool {
+	return s.optIn == nil || *s.optIn
+}
+
+// defaultMetadata are the structured metadata keys added by log.Pod.Metadata.
+var defaultMetadata = []string{"traceID", "pod", "user", "node"}
+
+var (
+	apacheFields   = []string{"host", "user", "timestamp", "method", "request", "protocol", "status", "bytes"}
+	jsonLogFields  = []string{"host", "user-identifier", "datetime", "method", "request", "protocol", "status", "bytes", "referer", "_25values", "msg", "nested_object"}
+	grpcLogFields  = []string{"ts", "caller", "tenant", "level", "method", "duration", "msg", "err"}
+	tempoFields    = []string{"level", "ts", "caller", "msg", "key", "version", "oldVersion", "content", "oldContent", "bytes", "objects", "values", "seconds", "userid", "blockID", "err", "tenant", "active_series"}
+	shoppingFields = []string{"orderId", "customerId", "price", "paymentMethod", "shippingMethod", "shippingCountry"}
+)
+
+var registerMu sync.Mutex
+
+// register adds a service to services, replacing the service of the same
+// namespace and name if any. Services added in their own files call it from
+// init().
+func register(info ServiceInfo, gen LogGenerator) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	info.generator = gen
+	for i, s := range services {
+		if s.Namespace == info.Namespace && s.Name == info.Name {
+			services[i] = info
+			return
+		}
+	}
+	services = append(services, info)
+}
+
+// isOtelService reports whether svc is shipped through the OTel collector.
+func isOtelService(svc model.LabelValue) bool {
+	return strings.Contains(string(svc), "-otel")
+}
+
+// lookupService returns the registered service of namespace and name.
+func lookupService(namespace, name model.LabelValue) (ServiceInfo, bool) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for _, s := range services {
+		if s.Namespace == namespace && s.Name == name {
+			return s, true
+		}
+	}
+	return ServiceInfo{}, false
+}
+
+// registeredServices returns every service the generator emits, sorted by
+// namespace and name.
+func registeredServices() []ServiceInfo {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	out := append([]ServiceInfo(nil), services...)
+	for i := range out {
+		out[i].OTel = isOtelService(out[i].Name)
+		out[i].E2ECritical = log.IsE2ECritical(out[i].Name)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// streamMetadata returns the structured metadata of the entries of s, given
+// the metadata of their stream.
+func (s ServiceInfo) streamMetadata(metadata push.LabelsAdapter) push.LabelsAdapter {
+	if s.noMetadata {
+		return push.LabelsAdapter{}
+	}
+	return metadata
+}
+
+// listServices writes the registered services matched by selector to w as a
+// table or as JSON.
+func listServices(w io.Writer, format string, selector *serviceSelector) error {
+	var matched []ServiceInfo
+	for _, s := range registeredServices() {
+		if selector.Matches(s.Namespace, s.Name) {
+			matched = append(matched, s)
+		}
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matched)
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tSERVICE\tFORMAT\tOTEL\tE2E\tMETADATA\tDESCRIPTION")
+		for _, s := range matched {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%t\t%s\t%s\n", s.Namespace, s.Name, s.Format, s.OTel, s.E2ECritical, strings.Join(s.Metadata, ","), s.Description)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown list format %q, expected table or json", format)
+	}
+}
+
+// describeService writes the registry entry of service (either "service" or
+// "namespace/service") followed by n sample lines.
+func describeService(w io.Writer, service string, n int) error {
+	var matches []ServiceInfo
+	for _, s := range registeredServices() {
+		if string(s.Name) == service || string(s.Namespace)+"/"+string(s.Name) == service {
+			matches = append(matches, s)
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("unknown service %q, see -list", service)
+	}
+	for _, s := range matches {
+		fmt.Fprintf(w, "%s/%s\n", s.Namespace, s.Name)
+		fmt.Fprintf(w, "  description: %s\n", s.Description)
+		fmt.Fprintf(w, "  format:      %s\n", s.Format)
+		fmt.Fprintf(w, "  fields:      %s\n", strings.Join(s.Fields, ", "))
+		fmt.Fprintf(w, "  metadata:    %s\n", strings.Join(s.Metadata, ", "))
+		if s.ExpectedServiceName != "" {
+			fmt.Fprintf(w, "  expected service_name: %s\n", s.ExpectedServiceName)
+		}
+		fmt.Fprintf(w, "  otel:        %t\n", s.OTel)
+		fmt.Fprintf(w, "  e2e:         %t\n", s.E2ECritical)
+		for _, d := range s.Distributions {
+			q := d.Quantiles()
+			fmt.Fprintf(w, "  %s: %s p50=%s p90=%s p95=%s p99=%s\n", d.Field, d.Dist, q["p50"], q["p90"], q["p95"], q["p99"])
+		}
+		fmt.Fprintln(w, "  samples:")
+		for _, line := range sampleLines(s, n) {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+	return nil
+}
+
+// sampleLines runs the generator of s against an in-memory logger until it
+// has produced n lines.
+func sampleLines(s ServiceInfo, n int) []string {
+	if s.generator == nil || n <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var lines []string
+	capture := log.LoggerFunc(func(labels model.LabelSet, _ time.Time, message string, metadata push.LabelsAdapter) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(lines) < n {
+			lines = append(lines, fmt.Sprintf("%s %s %v", labels, message, metadata))
+		}
+		if len(lines) >= n {
+			cancel()
+		}
+		return nil
+	})
+
+	scheduler := log.NewScheduler()
+	start := func(labels model.LabelSet, metadata push.LabelsAdapter) {
+		appLogger := log.NewAppLogger(labels, capture)
+		appLogger.SetScheduler(scheduler)
+		appLogger.SetInterval(func() time.Duration { return time.Millisecond })
+		s.generator(ctx, appLogger, metadata)
+	}
+	if s.Standalone {
+		start(s.stream())
+	} else {
+		started := false
+		log.ForAllClusters(s.Namespace, s.Name, func(labels model.LabelSet, metadata push.LabelsAdapter) {
+			if started {
+				return
+			}
+			started = true
+			start(labels, s.streamMetadata(metadata))
+		})
+	}
+	ctx, cancelTimeout := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelTimeout()
+	scheduler.Run(ctx, 1)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return lines
+}
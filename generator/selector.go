@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// serviceSelector decides which services are started, based on the -include
+// and -exclude flags. Each flag accepts either comma separated globs matched
+// against "namespace/service" (or just "service" when the glob has no slash),
+// e.g. "mimir-*/*,nginx", or a label selector over namespace and
+// service_name, e.g. `namespace=~"mimir.*",service_name!="nginx"`.
+type serviceSelector struct {
+	include []serviceMatcher
+	exclude []serviceMatcher
+}
+
+type serviceMatcher interface {
+	matches(namespace, service model.LabelValue) bool
+}
+
+// newServiceSelector parses the -include and -exclude flag values.
+func newServiceSelector(include, exclude string) (*serviceSelector, error) {
+	inc, err := parseServiceMatchers(include)
+	if err != nil {
+		return nil, fmt.Errorf("-include: %w", err)
+	}
+	exc, err := parseServiceMatchers(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("-exclude: %w", err)
+	}
+	return &serviceSelector{include: inc, exclude: exc}, nil
+}
+
+// Matches reports whether the service should be started. A nil selector
+// matches everything.
+func (s *serviceSelector) Matches(namespace, service model.LabelValue) bool {
+	if s == nil {
+		return true
+	}
+	for _, m := range s.exclude {
+		if m.matches(namespace, service) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, m := range s.include {
+		if m.matches(namespace, service) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseServiceMatchers(value string) ([]serviceMatcher, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(value, "{") || strings.Contains(value, "=") {
+		sel, err := parseLabelSelector(value)
+		if err != nil {
+			return nil, err
+		}
+		return []serviceMatcher{sel}, nil
+	}
+	var matchers []serviceMatcher
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		matchers = append(matchers, globMatcher(pattern))
+	}
+	return matchers, nil
+}
+
+// globMatcher matches "namespace/service" globs, or service globs when the
+// pattern has no slash.
+type globMatcher string
+
+func (g globMatcher) matches(namespace, service model.LabelValue) bool {
+	pattern := string(g)
+	if strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, string(namespace)+"/"+string(service))
+		return ok
+	}
+	ok, _ := path.Match(pattern, string(service))
+	return ok
+}
+
+type matchType string
+
+const (
+	matchEqual     matchType = "="
+	matchNotEqual  matchType = "!="
+	matchRegexp    matchType = "=~"
+	matchNotRegexp matchType = "!~"
+)
+
+type labelMatcher struct {
+	name  model.LabelName
+	typ   matchType
+	value string
+	re    *regexp.Regexp
+}
+
+func (m labelMatcher) matchesValue(v string) bool {
+	switch m.typ {
+	case matchEqual:
+		return v == m.value
+	case matchNotEqual:
+		return v != m.value
+	case matchRegexp:
+		return m.re.MatchString(v)
+	case matchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+	return false
+}
+
+// labelSelector matches when all of its matchers match.
+type labelSelector []labelMatcher
+
+func (s labelSelector) matches(namespace, service model.LabelValue) bool {
+	for _, m := range s {
+		v := string(service)
+		if m.name == "namespace" {
+			v = string(namespace)
+		}
+		if !m.matchesValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorMatcherRE matches one `name op "value"` matcher at the start of the input.
+var selectorMatcherRE = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*("(?:[^"\\]|\\.)*")\s*(?:,|$)`)
+
+// parseLabelSelector parses a LogQL-style stream selector restricted to the
+// namespace, service_name and service labels.
+func parseLabelSelector(value string) (labelSelector, error) {
+	input := strings.TrimSpace(value)
+	if strings.HasPrefix(input, "{") {
+		if !strings.HasSuffix(input, "}") {
+			return nil, fmt.Errorf("invalid selector %q: missing closing brace", value)
+		}
+		input = strings.TrimSpace(input[1 : len(input)-1])
+	}
+	var sel labelSelector
+	for input != "" {
+		m := selectorMatcherRE.FindStringSubmatch(input)
+		if m == nil {
+			return nil, fmt.Errorf("invalid selector %q near %q", value, input)
+		}
+		input = strings.TrimSpace(input[len(m[0]):])
+
+		name := model.LabelName(m[1])
+		switch name {
+		case "namespace", "service_name", "service":
+		default:
+			return nil, fmt.Errorf("invalid selector %q: unsupported label %q, expected namespace, service_name or service", value, name)
+		}
+		v, err := strconv.Unquote(m[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", value, err)
+		}
+		matcher := labelMatcher{name: name, typ: matchType(m[2]), value: v}
+		if matcher.typ == matchRegexp || matcher.typ == matchNotRegexp {
+			// Anchored like Prometheus and Loki matchers.
+			if matcher.re, err = regexp.Compile("^(?:" + v + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %w", value, err)
+			}
+		}
+		sel = append(sel, matcher)
+	}
+	if len(sel) == 0 {
+		return nil, fmt.Errorf("invalid selector %q: no matchers", value)
+	}
+	return sel, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceSelectorGlobs(t *testing.T) {
+	sel, err := newServiceSelector("mimir-*/*,nginx", "*/mimir-ruler")
+	require.NoError(t, err)
+
+	assert.True(t, sel.Matches("mimir-dev", "mimir-ingester"))
+	assert.True(t, sel.Matches("gateway", "nginx"))
+	assert.False(t, sel.Matches("mimir-dev", "mimir-ruler"), "exclude wins over include")
+	assert.False(t, sel.Matches("gateway", "nginx-json"))
+	assert.False(t, sel.Matches("mimir", "mimir-ingester"), "mimir-* does not match mimir")
+}
+
+func TestServiceSelectorLabelSelector(t *testing.T) {
+	sel, err := newServiceSelector(`{namespace=~"mimir.*", service_name!="mimir-ruler"}`, "")
+	require.NoError(t, err)
+
+	assert.True(t, sel.Matches("mimir", "mimir-ingester"))
+	assert.True(t, sel.Matches("mimir-dev", "mimir-querier"))
+	assert.False(t, sel.Matches("mimir-dev", "mimir-ruler"))
+	assert.False(t, sel.Matches("tempo-dev", "tempo-ingester"))
+
+	sel, err = newServiceSelector("", `service_name=~"tempo-.*|nginx"`)
+	require.NoError(t, err)
+	assert.False(t, sel.Matches("tempo-dev", "tempo-ingester"))
+	assert.False(t, sel.Matches("gateway", "nginx"))
+	assert.True(t, sel.Matches("gateway", "nginx-json"), "regexps are anchored")
+}
+
+func TestServiceSelectorEmptyMatchesAll(t *testing.T) {
+	sel, err := newServiceSelector("", "")
+	require.NoError(t, err)
+	assert.True(t, sel.Matches("gateway", "apache"))
+
+	var nilSel *serviceSelector
+	assert.True(t, nilSel.Matches("gateway", "apache"))
+}
+
+func TestServiceSelectorInvalid(t *testing.T) {
+	for _, value := range []string{
+		`{namespace="gateway"`,
+		`pod="abc"`,
+		`namespace=gateway`,
+		`namespace=~"("`,
+		"[",
+	} {
+		_, err := newServiceSelector(value, "")
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// serviceNameShape is a stream pushed without a service_name label, to
+// exercise Loki's service name discovery and Drilldown's unknown_service
+// handling.
+type serviceNameShape struct {
+	name        model.LabelValue
+	description string
+	// labels are added to the cluster and namespace labels of the stream.
+	labels model.LabelSet
+	// otelServiceName is the OTel service.name resource attribute, for
+	// shapes shipped through the OTel collector instead of labels.
+	otelServiceName string
+}
+
+// serviceNameShapes are the services of the service-discovery namespace,
+// started with -service-name-discovery.
+var serviceNameShapes = []serviceNameShape{
+	{name: "app-label", description: "only an app label", labels: model.LabelSet{"app": "storefront"}},
+	{name: "k8s-deployment-label", description: "only a k8s_deployment_name label, which is not in Loki's default discover_service_name", labels: model.LabelSet{"k8s_deployment_name": "billing"}},
+	{name: "container-label", description: "only a container label", labels: model.LabelSet{"container": "auth-proxy"}},
+	{name: "job-label", description: "only a job label", labels: model.LabelSet{"job": "service-discovery/metrics-scraper"}},
+	{name: "service-name-otel", description: "no labels, shipped over OTLP with the service.name resource attribute", otelServiceName: "recommendations"},
+	{name: "no-service-labels", description: "none of the labels Loki derives a service name from"},
+}
+
+// expectedServiceName is the service_name Loki derives for streams of s.
+func (s serviceNameShape) expectedServiceName() string {
+	if s.otelServiceName != "" {
+		return s.otelServiceName
+	}
+	return log.DiscoveredServiceName(s.stream())
+}
+
+// stream returns the labels of the only stream of s, in the first cluster.
+func (s serviceNameShape) stream() model.LabelSet {
+	labels := model.LabelSet{
+		"cluster":   model.LabelValue(log.Clusters[0]),
+		"namespace": "service-discovery",
+	}
+	return labels.Merge(s.labels)
+}
+
+func (s serviceNameShape) pod() string {
+	return string(s.name) + "-" + log.ReplicaSetHash(log.Clusters[0], "service-discovery", s.name, 0) + "-0"
+}
+
+// metadata returns the structured metadata of the only stream of s.
+func (s serviceNameShape) metadata() push.LabelsAdapter {
+	return push.LabelsAdapter{{Name: "pod", Value: s.pod()}, {Name: "node", Value: log.PodNode(log.Clusters[0], s.pod())}}
+}
+
+func init() {
+	for _, shape := range serviceNameShapes {
+		shape := shape
+		register(ServiceInfo{
+			Namespace:           "service-discovery",
+			Name:                shape.name,
+			Description:         "Stream without service_name: " + shape.description + "; started with -service-name-discovery",
+			Format:              "logfmt",
+			Fields:              []string{"level", "msg", "shape", "expected_service_name"},
+			Metadata:            defaultMetadata,
+			ExpectedServiceName: shape.expectedServiceName(),
+			Standalone:          true,
+			stream: func() (model.LabelSet, push.LabelsAdapter) {
+				return shape.stream(), shape.metadata()
+			},
+		}, serviceNameShapeGenerator(shape))
+	}
+}
+
+// serviceNameShapeGenerator logs lines naming the shape and the service
+// name Loki is expected to derive for it.
+func serviceNameShapeGenerator(shape serviceNameShape) LogGenerator {
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		logger.Schedule(func(t time.Time) {
+			level := log.RandLevel()
+			line := fmt.Sprintf(`level=%s msg="service name discovery" shape=%s expected_service_name=%s`, level, shape.name, shape.expectedServiceName())
+			logger.LogWithMetadata(level, t, line, log.RandStreamMetadata(metadata))
+		})
+	}
+}
+
+// startServiceNameShapes starts the services of the service-discovery
+// namespace matched by selector. OTel shapes are only started with useOtel.
+func startServiceNameShapes(ctx context.Context, logger log.Logger, useOtel bool, selector *serviceSelector) {
+	for _, shape := range serviceNameShapes {
+		if !selector.Matches("service-discovery", shape.name) {
+			continue
+		}
+		shapeLogger := logger
+		if shape.otelServiceName != "" {
+			if !useOtel {
+				continue
+			}
+			shapeLogger = log.NewOtelLogger(shape.otelServiceName, shape.stream())
+		}
+		serviceNameShapeGenerator(shape)(ctx, log.NewAppLogger(shape.stream(), shapeLogger), shape.metadata())
+	}
+}
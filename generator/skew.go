@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// Weighted skews of the timestamp-skew services, as set by -skews and
+// -line-drifts.
+var (
+	timestampSkews      = log.TimestampSkews
+	lineTimestampDrifts = log.LineTimestampDrifts
+)
+
+func init() {
+	register(ServiceInfo{
+		Namespace:   "timestamp-skew",
+		Name:        "skewed-entries",
+		Description: "Entries out of order, older than reject_old_samples_max_age, in the future and exactly duplicated, with the skew in structured metadata. Old and future entries are relative to the wall clock, even in static mode, so they are not reproducible; started with -timestamp-skew, see -skews",
+		Format:      "logfmt",
+		Fields:      []string{"ts", "level", "msg", "skew", "offset", "seq"},
+		Metadata:    append(append([]string{}, defaultMetadata...), "skew"),
+		Standalone:  true,
+		stream:      timestampSkewStream("skewed-entries"),
+	}, skewedEntriesGenerator)
+	register(ServiceInfo{
+		Namespace:   "timestamp-skew",
+		Name:        "line-timestamp-drift",
+		Description: "Lines whose ts field differs from the entry timestamp, with the difference in structured metadata; started with -timestamp-skew, see -line-drifts",
+		Format:      "logfmt",
+		Fields:      []string{"ts", "level", "msg", "drift"},
+		Metadata:    append(append([]string{}, defaultMetadata...), "drift", "line_ts_offset"),
+		Standalone:  true,
+		stream:      timestampSkewStream("line-timestamp-drift"),
+	}, lineTimestampDriftGenerator)
+}
+
+// timestampSkewStream returns the stream of svc, in the first cluster.
+func timestampSkewStream(svc model.LabelValue) func() (model.LabelSet, push.LabelsAdapter) {
+	return func() (model.LabelSet, push.LabelsAdapter) {
+		cluster := log.Clusters[0]
+		labels := model.LabelSet{
+			"cluster":      model.LabelValue(cluster),
+			"namespace":    "timestamp-skew",
+			"service_name": svc,
+		}
+		pod := string(svc) + "-" + log.ReplicaSetHash(cluster, "timestamp-skew", svc, 0) + "-0"
+		return labels, push.LabelsAdapter{{Name: "pod", Value: pod}}
+	}
+}
+
+// skewedEntriesGenerator moves the timestamp of entries by the skews in
+// timestampSkews. The line carries the entry timestamp and a sequence number,
+// so rejected and deduplicated entries can be counted.
+func skewedEntriesGenerator(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+	skews := timestampSkews
+	var seq atomic.Int64
+	type entry struct {
+		level    model.LabelValue
+		t        time.Time
+		line     string
+		metadata push.LabelsAdapter
+	}
+	var previous *entry
+	logger.Schedule(func(t time.Time) {
+		skew, ok := log.RandSkew(skews)
+		if !ok {
+			return
+		}
+		if skew.Name == log.SkewDuplicate {
+			if previous != nil {
+				logger.LogWithMetadata(previous.level, previous.t, previous.line, previous.metadata)
+				return
+			}
+			skew = skews[0]
+		}
+		ts, offset := skew.Time(t)
+		level := log.RandLevel()
+		line := fmt.Sprintf(`ts=%s level=%s msg="skewed entry" skew=%s offset=%s seq=%d`, ts.UTC().Format(time.RFC3339Nano), level, skew.Name, offset, seq.Add(1))
+		md := append(log.RandStreamMetadata(metadata), push.LabelAdapter{Name: "skew", Value: skew.Name})
+		previous = &entry{level, ts, line, md}
+		logger.LogWithMetadata(level, ts, line, md)
+	})
+}
+
+// lineTimestampDriftGenerator writes lines whose ts field is moved by the
+// drifts in lineTimestampDrifts, at the entry timestamp.
+func lineTimestampDriftGenerator(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+	drifts := lineTimestampDrifts
+	logger.Schedule(func(t time.Time) {
+		drift, ok := log.RandSkew(drifts)
+		if !ok {
+			return
+		}
+		offset := drift.Offset()
+		level := log.RandLevel()
+		line := fmt.Sprintf(`ts=%s level=%s msg="line timestamp drift" drift=%s`, t.Add(offset).UTC().Format(time.RFC3339Nano), level, drift.Name)
+		md := append(log.RandStreamMetadata(metadata),
+			push.LabelAdapter{Name: "drift", Value: drift.Name},
+			push.LabelAdapter{Name: "line_ts_offset", Value: offset.String()},
+		)
+		logger.LogWithMetadata(level, t, line, md)
+	})
+}
+
+// startTimestampSkew starts the services of the timestamp-skew namespace
+// matched by selector. logger must write lines unchanged, so duplicates
+// repeat the previous entry exactly.
+func startTimestampSkew(ctx context.Context, logger log.Logger, selector *serviceSelector) {
+	for _, svc := range registeredServices() {
+		if svc.Namespace != "timestamp-skew" || !selector.Matches(svc.Namespace, svc.Name) {
+			continue
+		}
+		labels, metadata := svc.stream()
+		svc.generator(ctx, log.NewAppLogger(labels, logger), metadata)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdlog "log"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/grafana/explore-logs/generator/flog"
+	"github.com/grafana/explore-logs/generator/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+)
+
+// templateService describes a service whose lines are rendered from a
+// flog.Template, as loaded from the -templates file:
+//
+//	[
+//	  {"namespace": "gateway", "service": "haproxy", "template": "{{ipv4}} [{{ts \"apache\"}}] {{method}} {{uri}} {{status}}"}
+//	]
+type templateService struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	Template  string `json:"template"`
+}
+
+// loadTemplateServices reads template-defined services from path and adds
+// them to the registry.
+func loadTemplateServices(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("templates: %w", err)
+	}
+	var services []templateService
+	if err := json.Unmarshal(data, &services); err != nil {
+		return fmt.Errorf("templates: parsing %s: %w", path, err)
+	}
+	for _, svc := range services {
+		if svc.Namespace == "" || svc.Service == "" {
+			return fmt.Errorf("templates: namespace and service are required (template %q)", svc.Template)
+		}
+		tpl, err := flog.NewTemplate(svc.Service, svc.Template, templateLogFuncs)
+		if err != nil {
+			return fmt.Errorf("templates: %w", err)
+		}
+		// Most execution errors, e.g. bad arguments, fail every line.
+		if _, err := tpl.Execute(time.Now(), string(log.INFO)); err != nil {
+			return fmt.Errorf("templates: %s/%s: %w", svc.Namespace, svc.Service, err)
+		}
+		register(ServiceInfo{
+			Namespace:   model.LabelValue(svc.Namespace),
+			Name:        model.LabelValue(svc.Service),
+			Description: "Template-defined service loaded from " + path,
+			Format:      "template",
+			Metadata:    defaultMetadata,
+		}, templateGenerator(tpl))
+	}
+	return nil
+}
+
+// templateLogFuncs are the template functions drawing from the value pools
+// and helpers of the log package, in addition to those of flog.Template.
+var templateLogFuncs = template.FuncMap{
+	"uri":      log.RandURI,
+	"orgID":    log.RandOrgID,
+	"userID":   log.RandUserID,
+	"seq":      log.RandSeq,
+	"duration": log.RandDuration,
+	"error":    log.RandError,
+	"fileName": log.RandFileName,
+	"traceID":  func() string { return log.RandTraceID("") },
+	"randLevel": func() string {
+		return string(log.RandLevel())
+	},
+}
+
+// templateGenerator returns a LogGenerator that renders every line from tpl.
+// Lines failing to render are dropped, and only the first failure of tpl is
+// logged.
+func templateGenerator(tpl *flog.Template) LogGenerator {
+	var failed sync.Once
+	return func(ctx context.Context, logger *log.AppLogger, metadata push.LabelsAdapter) {
+		renderer := tpl.Renderer(streamFlog(logger, metadata))
+		logger.Schedule(func(t time.Time) {
+			level := log.RandLevel()
+			line, err := renderer.Execute(t, string(level))
+			if err != nil {
+				failed.Do(func() { stdlog.Printf("generator: rendering template of %s: %v", logger.Labels()["service_name"], err) })
+			} else {
+				logger.LogWithMetadata(level, t, line, metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTemplateServicesValidatesExecution(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+	for template, msg := range map[string]string{
+		`{{unknownFunc}}`:            "unknown functions fail to parse",
+		`{{weighted \"a\" 1 \"b\"}}`: "templates failing to execute are rejected when loaded",
+	} {
+		assert.NoError(t, os.WriteFile(path, []byte(`[{"namespace":"test","service":"broken","template":"`+template+`"}]`), 0o644))
+		assert.Error(t, loadTemplateServices(path), msg)
+	}
+}
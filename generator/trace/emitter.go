@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/common/model"
 	"go.opentelemetry.io/otel/attribute"
@@ -13,6 +14,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -110,6 +112,41 @@ func (e *Emitter) EmitSpan(ctx context.Context, serviceName, spanName string, la
 	return traceID
 }
 
+// StartSpan starts a span of serviceName at start, as a child of the span in
+// ctx if there is one. The returned context carries the new span so calls to
+// other services can be started as its children. The caller ends the span
+// with span.End(oteltrace.WithTimestamp(end)).
+// When e is nil or has no provider for serviceName, the span is a
+// non-recording span that is never exported. It carries the span context of
+// the parent, so it shares its trace ID, and ending it leaves the parent
+// running.
+func (e *Emitter) StartSpan(ctx context.Context, serviceName, spanName string, start time.Time, labels model.LabelSet) (context.Context, oteltrace.Span) {
+	if e == nil || e.conn == nil {
+		return ctx, nonRecordingSpan(ctx)
+	}
+
+	tp := e.getProvider(serviceName)
+	if tp == nil {
+		return ctx, nonRecordingSpan(ctx)
+	}
+
+	ctx, span := tp.Tracer("log-generator").Start(ctx, spanName, oteltrace.WithTimestamp(start), oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+	for k, v := range labels {
+		span.SetAttributes(attribute.String(string(k), string(v)))
+	}
+	if traceEmitterDebugLogging() {
+		log.Printf("trace emitter: started span service=%s name=%s traceID=%s", serviceName, spanName, span.SpanContext().TraceID())
+	}
+	return ctx, span
+}
+
+// nonRecordingSpan returns a span doing nothing, with the span context of
+// the span in ctx. Unlike oteltrace.SpanFromContext, it is never the parent
+// span itself.
+func nonRecordingSpan(ctx context.Context) oteltrace.Span {
+	return oteltrace.SpanFromContext(oteltrace.ContextWithSpanContext(context.Background(), oteltrace.SpanContextFromContext(ctx)))
+}
+
 // Shutdown flushes and shuts down all tracer providers.
 func (e *Emitter) Shutdown(ctx context.Context) error {
 	if e == nil || e.conn == nil {